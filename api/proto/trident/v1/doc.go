@@ -0,0 +1,23 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tridentv1 holds the generated Go stubs for trident.proto: the
+// TridentServiceClient/Server pair, message types, and the grpc-gateway
+// REST-to-gRPC handler registered by pkg/grpcserver's gateway mux. Run
+// `buf generate` (buf.gen.yaml, at the repo root, drives protoc-gen-go,
+// protoc-gen-go-grpc, and protoc-gen-grpc-gateway) to produce them; like
+// any other generated code they are not checked into version control,
+// so a clean checkout needs a generate step before pkg/grpcserver or
+// pkg/commands' grpc client will build.
+package tridentv1
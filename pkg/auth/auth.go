@@ -0,0 +1,49 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth defines the CLI-side contract for proving an operator's
+// identity to a trident orchestrator, independent of which identity
+// provider sits in front of it.
+package auth
+
+import "net/http"
+
+// Authenticator decorates outgoing requests with whatever credentials are
+// required to pass the orchestrator's access controls. Implementations
+// live in subpackages, one per supported provider (e.g. cloudflare, oidc).
+type Authenticator interface {
+	// Authenticate mutates req in place, adding headers/cookies as needed.
+	// It may block to refresh or acquire credentials.
+	Authenticate(req *http.Request) error
+}
+
+// Identity describes the operator associated with an authenticated
+// request, as resolved server-side by whichever mechanism validated it.
+// It is attached to the request context so handlers can make
+// authorization decisions without re-parsing tokens.
+type Identity struct {
+	// Subject is the stable, provider-assigned identifier for the
+	// operator (the JWT "sub" claim for OIDC, or the Cloudflare Access
+	// "sub"/email for tunnel-based auth).
+	Subject string
+
+	// Email is the operator's email address, used for audit logging and
+	// display; it is not guaranteed to be unique across providers.
+	Email string
+
+	// Claims holds the raw claims the identity was derived from, for
+	// providers (like OIDC) that support mapping arbitrary claims to
+	// roles in pkg/authz.
+	Claims map[string]interface{}
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cloudflare implements auth.Authenticator for orchestrators that
+// sit behind Cloudflare Access / Argo Tunnel, where the tunnel daemon is
+// responsible for attaching the operator's identity to each request.
+package cloudflare
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// ArgoAuthenticator attaches a Cloudflare Access service token to outgoing
+// requests so that cloudflared will let them through the tunnel in front
+// of the orchestrator. The service token itself is provisioned out of
+// band (via the Cloudflare dashboard) and supplied through the
+// CF_ACCESS_CLIENT_ID / CF_ACCESS_CLIENT_SECRET environment variables.
+type ArgoAuthenticator struct {
+	URL *url.URL
+}
+
+// Authenticate adds the Cloudflare Access service token headers to req.
+// It returns an error if the service token environment variables are not
+// set, since a request without them will simply be rejected by the edge.
+func (a *ArgoAuthenticator) Authenticate(req *http.Request) error {
+	id := os.Getenv("CF_ACCESS_CLIENT_ID")
+	secret := os.Getenv("CF_ACCESS_CLIENT_SECRET")
+	if id == "" || secret == "" {
+		return fmt.Errorf("CF_ACCESS_CLIENT_ID and CF_ACCESS_CLIENT_SECRET must be set to authenticate via cloudflare")
+	}
+
+	req.Header.Set("CF-Access-Client-Id", id)
+	req.Header.Set("CF-Access-Client-Secret", secret)
+
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// Login drives an interactive authorization-code-with-PKCE flow: it
+// starts a loopback listener on the authenticator's RedirectURL, prints
+// the provider's authorization URL for the operator to open, and blocks
+// until the browser redirects back with a code (or ctx is canceled).
+// On success, the resulting ID and refresh tokens are cached to disk and
+// Authenticate will use them for subsequent requests.
+func (a *Authenticator) Login(ctx context.Context) error {
+	state, err := randomState()
+	if err != nil {
+		return fmt.Errorf("generating oauth2 state: %w", err)
+	}
+
+	verifier, challenge, err := newPKCEVerifier()
+	if err != nil {
+		return fmt.Errorf("generating pkce verifier: %w", err)
+	}
+
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+
+	srv, addr, err := startCallbackServer(a.cfg.RedirectURL, state, codeCh, errCh)
+	if err != nil {
+		return fmt.Errorf("starting pkce callback listener: %w", err)
+	}
+	defer srv.Shutdown(ctx) // nolint:errcheck
+
+	authURL := a.oauthCfg.AuthCodeURL(
+		state,
+		oauth2.SetAuthURLParam("code_challenge", challenge),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	log.Infof("open the following URL to authenticate with %s:", a.cfg.IssuerURL)
+	log.Infof("  %s", authURL)
+	log.Infof("waiting for redirect on %s...", addr)
+
+	var code string
+	select {
+	case code = <-codeCh:
+	case err := <-errCh:
+		return fmt.Errorf("oidc callback: %w", err)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	tok, err := a.oauthCfg.Exchange(ctx, code,
+		oauth2.SetAuthURLParam("code_verifier", verifier),
+	)
+	if err != nil {
+		return fmt.Errorf("exchanging authorization code: %w", err)
+	}
+
+	rawIDToken, ok := tok.Extra("id_token").(string)
+	if !ok {
+		return fmt.Errorf("token response did not contain an id_token")
+	}
+
+	verifier2 := a.provider.Verifier(&gooidc.Config{ClientID: a.cfg.ClientID})
+	if _, err := verifier2.Verify(ctx, rawIDToken); err != nil {
+		return fmt.Errorf("verifying id_token: %w", err)
+	}
+
+	a.mu.Lock()
+	a.token = &cachedToken{
+		IDToken:      rawIDToken,
+		RefreshToken: tok.RefreshToken,
+		Expiry:       tok.Expiry,
+	}
+	a.mu.Unlock()
+
+	return saveToken(a.token)
+}
+
+// startCallbackServer listens on the host:port parsed out of redirectURL
+// (e.g. "http://localhost:8585/callback") and sends the "code" query
+// parameter of the first matching request on codeCh, or any error
+// (including a state mismatch) on errCh. Binding to whatever the operator
+// configured, rather than a hardcoded port, keeps the listener consistent
+// with the redirect_uri actually sent to the provider in AuthCodeURL.
+func startCallbackServer(redirectURL, state string, codeCh chan<- string, errCh chan<- error) (*http.Server, string, error) {
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("parsing redirect url %q: %w", redirectURL, err)
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/callback"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("state"); got != state {
+			errCh <- fmt.Errorf("state mismatch: got %q", got)
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			return
+		}
+
+		code := r.URL.Query().Get("code")
+		if code == "" {
+			errCh <- fmt.Errorf("no code in callback request")
+			http.Error(w, "missing code", http.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprint(w, "login complete, you may close this window")
+		codeCh <- code
+	})
+
+	srv := &http.Server{Handler: mux}
+
+	// bind explicitly so we can report the chosen address back to the caller
+	addr := u.Host
+	srv.Addr = addr
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	return srv, addr, nil
+}
+
+func randomState() (string, error) {
+	verifier, _, err := newPKCEVerifier()
+	return verifier, err
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"testing"
+)
+
+// TestStartCallbackServerBindsConfiguredAddress is a regression test for
+// a bug where the callback listener always bound 127.0.0.1:8585
+// regardless of what RedirectURL the operator configured, so a
+// non-default redirect_uri would silently never get a listener.
+func TestStartCallbackServerBindsConfiguredAddress(t *testing.T) {
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+
+	srv, addr, err := startCallbackServer("http://127.0.0.1:0/callback", "state", codeCh, errCh)
+	if err != nil {
+		t.Fatalf("startCallbackServer() error = %v", err)
+	}
+	defer srv.Shutdown(context.Background()) // nolint:errcheck
+
+	if addr != "127.0.0.1:0" {
+		t.Fatalf("addr = %q, want the host:port parsed out of the redirect url", addr)
+	}
+	if srv.Addr != addr {
+		t.Fatalf("srv.Addr = %q, want %q", srv.Addr, addr)
+	}
+}
+
+func TestStartCallbackServerRejectsInvalidRedirectURL(t *testing.T) {
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+
+	if _, _, err := startCallbackServer("://not-a-url", "state", codeCh, errCh); err == nil {
+		t.Fatal("startCallbackServer() with an unparseable redirect url, want error")
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oidc implements auth.Authenticator against any standards
+// compliant OIDC/OAuth2 identity provider (Dex, Keycloak, Okta, Google,
+// Azure AD, ...), so that operators can run trident without depending on
+// a Cloudflare Tunnel in front of the orchestrator.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// tokenFile is where the CLI caches the operator's ID and refresh tokens
+// between invocations, scoped to the local user only.
+const tokenFile = ".trident/tokens.json"
+
+// Config describes how to reach and authenticate against an OIDC
+// provider. It is populated from the `auth.oidc` section of the trident
+// CLI config file.
+type Config struct {
+	// IssuerURL is the provider's issuer, e.g.
+	// "https://dex.example.com" or "https://accounts.google.com".
+	IssuerURL string
+
+	// ClientID and ClientSecret identify the trident-cli OAuth2 client.
+	// ClientSecret may be empty for public clients relying solely on PKCE.
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is the loopback address the provider redirects back
+	// to after the operator authenticates, e.g. "http://localhost:8585/callback".
+	RedirectURL string
+
+	// Scopes requested in addition to "openid"; most providers expect
+	// "profile" and "email" here.
+	Scopes []string
+}
+
+// cachedToken is the on-disk representation of an operator's session.
+type cachedToken struct {
+	IDToken      string    `json:"id_token"`
+	RefreshToken string    `json:"refresh_token"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// Authenticator is an auth.Authenticator backed by an OIDC authorization
+// code + PKCE flow. It transparently refreshes the cached token when it
+// has expired, so callers only need to construct it once at startup.
+type Authenticator struct {
+	cfg      Config
+	provider *gooidc.Provider
+	oauthCfg oauth2.Config
+
+	mu    sync.Mutex
+	token *cachedToken
+}
+
+// New discovers the provider's endpoints via its well-known configuration
+// and returns an Authenticator ready to Login or Authenticate with.
+func New(ctx context.Context, cfg Config) (*Authenticator, error) {
+	provider, err := gooidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("discovering oidc provider %s: %w", cfg.IssuerURL, err)
+	}
+
+	scopes := append([]string{gooidc.ScopeOpenID}, cfg.Scopes...)
+
+	a := &Authenticator{
+		cfg:      cfg,
+		provider: provider,
+		oauthCfg: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+	}
+
+	if tok, err := loadToken(); err == nil {
+		a.token = tok
+	}
+
+	return a, nil
+}
+
+// Authenticate ensures a valid ID token is cached, refreshing it if
+// necessary, and attaches it to req as a Bearer token.
+func (a *Authenticator) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == nil {
+		return fmt.Errorf("not logged in: run `trident-cli login` first")
+	}
+
+	if time.Now().After(a.token.Expiry) {
+		if err := a.refreshLocked(req.Context()); err != nil {
+			return fmt.Errorf("refreshing oidc token: %w", err)
+		}
+	}
+
+	req.Header.Set("Authorization", "Bearer "+a.token.IDToken)
+	return nil
+}
+
+// refreshLocked exchanges the cached refresh token for a new ID token.
+// Callers must hold a.mu.
+func (a *Authenticator) refreshLocked(ctx context.Context) error {
+	src := a.oauthCfg.TokenSource(ctx, &oauth2.Token{RefreshToken: a.token.RefreshToken})
+
+	tok, err := src.Token()
+	if err != nil {
+		return err
+	}
+
+	rawIDToken, ok := tok.Extra("id_token").(string)
+	if !ok {
+		return fmt.Errorf("token response did not contain an id_token")
+	}
+
+	a.token = &cachedToken{
+		IDToken:      rawIDToken,
+		RefreshToken: tok.RefreshToken,
+		Expiry:       tok.Expiry,
+	}
+
+	return saveToken(a.token)
+}
+
+// newPKCEVerifier returns a random, URL-safe code verifier suitable for
+// RFC 7636 PKCE, along with its S256 challenge.
+func newPKCEVerifier() (verifier string, challenge string, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(buf)
+
+	sum := sha256.Sum256([]byte(verifier))
+	challenge = base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return verifier, challenge, nil
+}
+
+func tokenPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, tokenFile), nil
+}
+
+func loadToken() (*cachedToken, error) {
+	path, err := tokenPath()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tok cachedToken
+	if err := json.Unmarshal(b, &tok); err != nil {
+		return nil, err
+	}
+
+	return &tok, nil
+}
+
+func saveToken(tok *cachedToken) error {
+	path, err := tokenPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("caching refreshed oidc token")
+	return os.WriteFile(path, b, 0600)
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+)
+
+func TestNewPKCEVerifierChallengeMatchesS256OfVerifier(t *testing.T) {
+	verifier, challenge, err := newPKCEVerifier()
+	if err != nil {
+		t.Fatalf("newPKCEVerifier() error = %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	want := base64.RawURLEncoding.EncodeToString(sum[:])
+	if challenge != want {
+		t.Fatalf("challenge = %q, want S256(verifier) = %q", challenge, want)
+	}
+}
+
+func TestNewPKCEVerifierIsUnpredictable(t *testing.T) {
+	verifier1, challenge1, err := newPKCEVerifier()
+	if err != nil {
+		t.Fatalf("newPKCEVerifier() error = %v", err)
+	}
+	verifier2, challenge2, err := newPKCEVerifier()
+	if err != nil {
+		t.Fatalf("newPKCEVerifier() error = %v", err)
+	}
+
+	if verifier1 == verifier2 {
+		t.Fatal("newPKCEVerifier() returned the same verifier twice")
+	}
+	if challenge1 == challenge2 {
+		t.Fatal("newPKCEVerifier() returned the same challenge twice")
+	}
+}
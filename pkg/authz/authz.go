@@ -0,0 +1,134 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package authz is trident's single policy-evaluation chokepoint: every
+// handler that touches a campaign calls Check instead of rolling its own
+// role logic, so the RBAC rules live in one place.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/praetorian-inc/trident/pkg/auth"
+	"github.com/praetorian-inc/trident/pkg/db"
+)
+
+// ErrForbidden is wrapped by every error Check returns because identity
+// lacked the required role, as opposed to an error looking up that
+// role (e.g. a database failure). Callers use errors.Is(err, ErrForbidden)
+// to pick an HTTP 403 / gRPC PermissionDenied over a 500 / Internal.
+var ErrForbidden = errors.New("forbidden")
+
+// Action identifies an operation being attempted against a resource.
+type Action string
+
+const (
+	ActionCreateCampaign Action = "campaign:create"
+	ActionReadCampaign   Action = "campaign:read"
+	ActionMutateCampaign Action = "campaign:mutate" // pause/resume/cancel/delete/clone
+	ActionReadResults    Action = "campaign:results:read"
+	ActionManageACL      Action = "campaign:acl:manage"
+	ActionReadAudit      Action = "audit:read"
+)
+
+// Resource identifies what an Action is being attempted against. A zero
+// CampaignID means the action is not scoped to a single campaign (e.g.
+// creating a new one, or reading the global audit log).
+type Resource struct {
+	CampaignID uint
+}
+
+// Evaluator checks whether an identity may perform an action, consulting
+// global roles and per-campaign ACLs stored in pkg/db.
+type Evaluator struct {
+	DB db.Datastore
+}
+
+// New returns an Evaluator backed by the given datastore.
+func New(datastore db.Datastore) *Evaluator {
+	return &Evaluator{DB: datastore}
+}
+
+// Check returns nil if identity may perform action on resource, or an
+// error describing why not. Handlers should treat any error as a 403.
+func (e *Evaluator) Check(ctx context.Context, identity auth.Identity, action Action, resource Resource) error {
+	role, err := e.roleFor(identity, resource)
+	if err != nil {
+		return err
+	}
+
+	if allowed(role, action) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: subject %q with role %q may not perform %q on campaign %d", ErrForbidden, identity.Subject, role, action, resource.CampaignID)
+}
+
+// roleFor resolves the role identity holds for resource: its global
+// role for actions not scoped to a campaign, or its campaign-scoped
+// grant otherwise.
+func (e *Evaluator) roleFor(identity auth.Identity, resource Resource) (db.Role, error) {
+	if resource.CampaignID == 0 {
+		return e.GlobalRole(identity)
+	}
+
+	acl, err := e.DB.GetCampaignACL(resource.CampaignID)
+	if err != nil {
+		return "", fmt.Errorf("loading campaign acl: %w", err)
+	}
+
+	for _, entry := range acl {
+		if entry.Subject == identity.Subject {
+			return entry.Role, nil
+		}
+	}
+
+	return db.RoleViewer, nil
+}
+
+// GlobalRole returns the role identity holds for actions not scoped to
+// any one campaign (creating a campaign, listing every campaign).
+// Authentication alone grants no global role: a subject with no
+// GlobalRole row is RoleViewer, same as an unrecognized subject on a
+// campaign ACL, until an admin grants them RoleOperator or RoleAdmin.
+func (e *Evaluator) GlobalRole(identity auth.Identity) (db.Role, error) {
+	role, err := e.DB.GetGlobalRole(identity.Subject)
+	if err != nil {
+		return "", fmt.Errorf("loading global role: %w", err)
+	}
+
+	if role == "" {
+		return db.RoleViewer, nil
+	}
+
+	return role, nil
+}
+
+// allowed implements the static role -> action matrix. admin can do
+// anything; operator can do everything except manage ACLs; viewer is
+// read-only.
+func allowed(role db.Role, action Action) bool {
+	switch role {
+	case db.RoleAdmin:
+		return true
+	case db.RoleOperator:
+		return action != ActionManageACL
+	case db.RoleViewer:
+		return action == ActionReadCampaign || action == ActionReadResults
+	default:
+		return false
+	}
+}
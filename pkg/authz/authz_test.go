@@ -0,0 +1,118 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/praetorian-inc/trident/pkg/auth"
+	"github.com/praetorian-inc/trident/pkg/db"
+)
+
+// fakeDatastore implements db.Datastore with just enough behavior to
+// drive Evaluator: per-subject campaign ACLs and global roles, both
+// settable directly by the test.
+type fakeDatastore struct {
+	db.Datastore
+
+	acl         map[uint][]db.CampaignACL
+	globalRoles map[string]db.Role
+}
+
+func (f *fakeDatastore) GetCampaignACL(campaignID uint) ([]db.CampaignACL, error) {
+	return f.acl[campaignID], nil
+}
+
+func (f *fakeDatastore) GetGlobalRole(subject string) (db.Role, error) {
+	return f.globalRoles[subject], nil
+}
+
+func TestCheck(t *testing.T) {
+	const campaignID = 1
+
+	ds := &fakeDatastore{
+		acl: map[uint][]db.CampaignACL{
+			campaignID: {
+				{CampaignID: campaignID, Subject: "campaign-admin", Role: db.RoleAdmin},
+				{CampaignID: campaignID, Subject: "campaign-operator", Role: db.RoleOperator},
+				{CampaignID: campaignID, Subject: "campaign-viewer", Role: db.RoleViewer},
+			},
+		},
+		globalRoles: map[string]db.Role{
+			"global-admin":    db.RoleAdmin,
+			"global-operator": db.RoleOperator,
+		},
+	}
+	e := New(ds)
+
+	tests := []struct {
+		name       string
+		subject    string
+		action     Action
+		campaignID uint
+		wantErr    bool
+	}{
+		// A campaign-scoped grant governs a campaign-scoped action...
+		{"campaign admin may mutate", "campaign-admin", ActionMutateCampaign, campaignID, false},
+		{"campaign operator may mutate", "campaign-operator", ActionMutateCampaign, campaignID, false},
+		{"campaign viewer may read", "campaign-viewer", ActionReadCampaign, campaignID, false},
+		{"campaign viewer may not mutate", "campaign-viewer", ActionMutateCampaign, campaignID, true},
+		{"subject with no acl entry defaults to viewer", "stranger", ActionReadCampaign, campaignID, false},
+		{"subject with no acl entry may not mutate", "stranger", ActionMutateCampaign, campaignID, true},
+
+		// ...but a campaign-scoped grant never leaks into an unscoped
+		// action: only a GlobalRole counts there.
+		{"campaign admin may not create campaigns globally", "campaign-admin", ActionCreateCampaign, 0, true},
+
+		// Unscoped actions are gated by GlobalRole alone. This is the
+		// regression case for the bug where CampaignID == 0 always
+		// resolved to RoleOperator regardless of who was asking.
+		{"global operator may create campaigns", "global-operator", ActionCreateCampaign, 0, false},
+		{"global admin may list campaigns", "global-admin", ActionReadCampaign, 0, false},
+		{"authenticated subject with no global role may not create campaigns", "nobody-in-particular", ActionCreateCampaign, 0, true},
+		{"authenticated subject with no global role may still read", "nobody-in-particular", ActionReadCampaign, 0, false},
+
+		// Admins can manage ACLs; operators (global or per-campaign) cannot.
+		{"campaign admin may manage acl", "campaign-admin", ActionManageACL, campaignID, false},
+		{"campaign operator may not manage acl", "campaign-operator", ActionManageACL, campaignID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Check(context.Background(), auth.Identity{Subject: tt.subject}, tt.action, Resource{CampaignID: tt.campaignID})
+			if tt.wantErr && !errors.Is(err, ErrForbidden) {
+				t.Fatalf("Check() = %v, want an ErrForbidden", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Check() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestGlobalRoleDefaultsToViewer(t *testing.T) {
+	ds := &fakeDatastore{globalRoles: map[string]db.Role{}}
+	e := New(ds)
+
+	role, err := e.GlobalRole(auth.Identity{Subject: "nobody-in-particular"})
+	if err != nil {
+		t.Fatalf("GlobalRole() error = %v", err)
+	}
+	if role != db.RoleViewer {
+		t.Fatalf("GlobalRole() = %q, want %q", role, db.RoleViewer)
+	}
+}
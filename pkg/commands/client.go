@@ -0,0 +1,73 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/spf13/viper"
+)
+
+var tracer = otel.Tracer("github.com/praetorian-inc/trident/pkg/commands")
+
+// orchestratorRequest builds a request against the configured
+// orchestrator and signs it with the global authenticator, so every
+// subcommand talks to the server the same way. It starts a span
+// covering this invocation and injects it into the request as a
+// traceparent header, so the orchestrator's handler span (see
+// pkg/server.Instrument) is a child of it, continuing the same trace
+// from trident-cli through to the scheduler.
+func orchestratorRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	ctx, span := tracer.Start(ctx, "cli."+method+" "+path)
+	defer span.End()
+
+	url := fmt.Sprintf("%s%s", viper.GetString("orchestrator-url"), path)
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("building request to %s: %w", path, err)
+	}
+
+	if err := authenticator.Authenticate(req); err != nil {
+		return nil, fmt.Errorf("authenticating request: %w", err)
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return req, nil
+}
+
+// doOrchestratorRequest sends req and ensures the orchestrator accepted
+// it, returning an error that includes the response body on failure.
+func doOrchestratorRequest(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling orchestrator: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("orchestrator returned %s: %s", resp.Status, string(b))
+	}
+
+	return resp, nil
+}
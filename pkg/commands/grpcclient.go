@@ -0,0 +1,95 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	tridentv1 "github.com/praetorian-inc/trident/api/proto/trident/v1"
+)
+
+// transport selects which of trident-cli's two equivalent paths to the
+// orchestrator a command uses: "http" (the default, against pkg/server)
+// or "grpc" (against pkg/grpcserver). It is set from the `transport`
+// config key in root.go's init.
+var transport string
+
+// grpcConn lazily dials the orchestrator's gRPC listener the first time
+// a command needs it, and is reused by later calls in the same process.
+var grpcConn *grpc.ClientConn
+
+// grpcClient returns a tridentv1.TridentServiceClient dialed against
+// orchestrator-url, authenticated the same way orchestratorRequest signs
+// HTTP requests: it hands the global authenticator a throwaway request
+// and forwards whatever Authorization header it sets as gRPC metadata,
+// so neither transport has its own copy of the credential logic.
+func grpcClient(ctx context.Context) (tridentv1.TridentServiceClient, error) {
+	if grpcConn != nil {
+		return tridentv1.NewTridentServiceClient(grpcConn), nil
+	}
+
+	u, err := url.Parse(viper.GetString("orchestrator-url"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing orchestrator url: %w", err)
+	}
+
+	transportCreds := credentials.NewTLS(nil)
+	if u.Scheme == "http" {
+		transportCreds = insecure.NewCredentials()
+	}
+
+	conn, err := grpc.DialContext(ctx, u.Host,
+		grpc.WithTransportCredentials(transportCreds),
+		grpc.WithPerRPCCredentials(bearerFromAuthenticator{}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("dialing orchestrator: %w", err)
+	}
+
+	grpcConn = conn
+	return tridentv1.NewTridentServiceClient(grpcConn), nil
+}
+
+// bearerFromAuthenticator adapts the global auth.Authenticator (which
+// signs http.Requests) into grpc.PerRPCCredentials by signing a
+// throwaway request and lifting its Authorization header into metadata.
+type bearerFromAuthenticator struct{}
+
+func (bearerFromAuthenticator) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://orchestrator.invalid", nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request to sign: %w", err)
+	}
+
+	if err := authenticator.Authenticate(req); err != nil {
+		return nil, fmt.Errorf("authenticating request: %w", err)
+	}
+
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		return map[string]string{"authorization": auth}, nil
+	}
+
+	return nil, nil
+}
+
+func (bearerFromAuthenticator) RequireTransportSecurity() bool { return false }
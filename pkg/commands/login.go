@@ -0,0 +1,48 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/praetorian-inc/trident/pkg/auth/oidc"
+)
+
+// loginCmd drives an interactive OIDC login. It is a no-op (with a
+// warning) when auth.provider is set to "cloudflare", since the tunnel
+// handles authentication transparently in that mode.
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "authenticate to the orchestrator via the configured OIDC provider",
+	Run: func(cmd *cobra.Command, args []string) {
+		a, ok := authenticator.(*oidc.Authenticator)
+		if !ok {
+			log.Fatal("login is only required when auth.provider is \"oidc\"")
+		}
+
+		if err := a.Login(context.Background()); err != nil {
+			log.Fatalf("login failed: %s", err)
+		}
+
+		log.Info("logged in successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+}
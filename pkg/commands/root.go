@@ -15,6 +15,7 @@
 package commands
 
 import (
+	"context"
 	"net/url"
 
 	log "github.com/sirupsen/logrus"
@@ -23,10 +24,16 @@ import (
 
 	"github.com/praetorian-inc/trident/pkg/auth"
 	"github.com/praetorian-inc/trident/pkg/auth/cloudflare"
+	"github.com/praetorian-inc/trident/pkg/auth/oidc"
+	"github.com/praetorian-inc/trident/pkg/telemetry"
 )
 
 var authenticator auth.Authenticator
 
+// telemetryShutdown flushes any spans trident-cli buffered before the
+// process exits. It is a no-op unless telemetry.otlp-endpoint is set.
+var telemetryShutdown func(context.Context) error
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "trident-cli",
@@ -56,22 +63,73 @@ func init() {
 
 	log.Infof("Using config file: %s", viper.ConfigFileUsed())
 
+	// transport selects which of the orchestrator's two equivalent APIs
+	// commands talk to; it defaults to "http" so existing deployments
+	// (which may not run pkg/grpcserver at all) keep working.
+	switch transport = viper.GetString("transport"); transport {
+	case "":
+		transport = "http"
+	case "http", "grpc":
+	default:
+		log.Fatalf("unknown transport %q: must be \"http\" or \"grpc\"", transport)
+	}
+
 	// parse out the orchestrator server URL
-	url, err := url.Parse(viper.GetString("orchestrator-url"))
+	orchestratorURL, err := url.Parse(viper.GetString("orchestrator-url"))
 	if err != nil {
 		log.Fatalf("error parsing orchestrator url: %s", err)
 	}
 
 	// create the global authenticator that will be used to add an auth
-	// token to each command that needs it
-	authenticator = &cloudflare.ArgoAuthenticator{
-		URL: url,
+	// token to each command that needs it. auth.provider selects between
+	// the legacy Cloudflare Access tunnel and a standalone OIDC provider;
+	// it defaults to cloudflare so existing deployments keep working.
+	switch provider := viper.GetString("auth.provider"); provider {
+	case "", "cloudflare":
+		authenticator = &cloudflare.ArgoAuthenticator{
+			URL: orchestratorURL,
+		}
+	case "oidc":
+		a, err := oidc.New(context.Background(), oidc.Config{
+			IssuerURL:    viper.GetString("auth.oidc.issuer-url"),
+			ClientID:     viper.GetString("auth.oidc.client-id"),
+			ClientSecret: viper.GetString("auth.oidc.client-secret"),
+			RedirectURL:  viper.GetString("auth.oidc.redirect-url"),
+			Scopes:       viper.GetStringSlice("auth.oidc.scopes"),
+		})
+		if err != nil {
+			log.Fatalf("error configuring oidc authenticator: %s", err)
+		}
+		authenticator = a
+	default:
+		log.Fatalf("unknown auth.provider %q: must be \"cloudflare\" or \"oidc\"", provider)
+	}
+
+	// telemetry.otlp-endpoint is optional: leaving it unset keeps
+	// trident-cli from ever dialing a collector, while still
+	// propagating a traceparent header so the orchestrator's spans
+	// stay correlated if it has one configured.
+	var samplingRatio *float64
+	if viper.IsSet("telemetry.sampling-ratio") {
+		ratio := viper.GetFloat64("telemetry.sampling-ratio")
+		samplingRatio = &ratio
 	}
+
+	shutdown, err := telemetry.Init(context.Background(), "trident-cli", telemetry.Config{
+		OTLPEndpoint:  viper.GetString("telemetry.otlp-endpoint"),
+		SamplingRatio: samplingRatio,
+	})
+	if err != nil {
+		log.Fatalf("error configuring telemetry: %s", err)
+	}
+	telemetryShutdown = shutdown
 }
 
 // Execute is the entrypoint into the cmd line interface. It will execute the
 // desired subcommand and check for an error, reporting it if so
 func Execute() {
+	defer telemetryShutdown(context.Background())
+
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatalf("error during command execution: %s", err)
 	}
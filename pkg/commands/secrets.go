@@ -0,0 +1,87 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// secretCmd groups secret management subcommands.
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "manage provider credentials stored via pkg/secrets",
+}
+
+var secretPutCmd = &cobra.Command{
+	Use:   "put <value>",
+	Short: "store a secret and print its reference",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		body, err := json.Marshal(map[string]string{"Value": args[0]})
+		if err != nil {
+			log.Fatalf("error encoding secret: %s", err)
+		}
+
+		req, err := orchestratorRequest(cmd.Context(), http.MethodPost, "/secrets", bytes.NewReader(body))
+		if err != nil {
+			log.Fatalf("error building request: %s", err)
+		}
+
+		resp, err := doOrchestratorRequest(req)
+		if err != nil {
+			log.Fatalf("error storing secret: %s", err)
+		}
+		defer resp.Body.Close()
+
+		var out struct{ Ref string }
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			log.Fatalf("error decoding response: %s", err)
+		}
+
+		fmt.Println(out.Ref)
+	},
+}
+
+var secretDeleteCmd = &cobra.Command{
+	Use:   "delete <ref>",
+	Short: "delete a secret by its reference",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := fmt.Sprintf("/secrets/%s", url.PathEscape(args[0]))
+
+		req, err := orchestratorRequest(cmd.Context(), http.MethodDelete, path, nil)
+		if err != nil {
+			log.Fatalf("error building request: %s", err)
+		}
+
+		if _, err := doOrchestratorRequest(req); err != nil {
+			log.Fatalf("error deleting secret: %s", err)
+		}
+
+		log.Infof("secret %s deleted", args[0])
+	},
+}
+
+func init() {
+	secretCmd.AddCommand(secretPutCmd, secretDeleteCmd)
+	rootCmd.AddCommand(secretCmd)
+}
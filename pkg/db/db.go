@@ -0,0 +1,61 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package db defines trident's persistence layer: the campaign and
+// result schema, and the Datastore interface the HTTP handlers and
+// scheduler use to reach it. Concrete implementations (e.g. a Postgres
+// driver) live in subpackages.
+package db
+
+// Datastore is the storage interface trident's handlers and scheduler
+// depend on. It is implemented against Postgres in production; tests
+// and local development may substitute an in-memory implementation.
+type Datastore interface {
+	InsertCampaign(c *Campaign) error
+
+	// ListCampaign returns every campaign in the system; it is reserved
+	// for callers already known to hold RoleAdmin globally (see
+	// pkg/authz.Evaluator.GlobalRole). Everyone else is scoped via
+	// ListCampaignsForSubject.
+	ListCampaign() ([]Campaign, error)
+
+	// ListCampaignsForSubject returns only the campaigns subject holds
+	// a CampaignACL grant on.
+	ListCampaignsForSubject(subject string) ([]Campaign, error)
+
+	DescribeCampaign(q Query) (Campaign, error)
+	UpdateCampaignStatus(id uint, status CampaignStatus) error
+	DeleteCampaign(id uint) error
+	SelectResults(q Query) ([]Result, error)
+
+	// GetCampaignACL and SetCampaignACL manage per-campaign ownership
+	// and role grants, consumed by pkg/authz's policy evaluator.
+	GetCampaignACL(campaignID uint) ([]CampaignACL, error)
+	SetCampaignACL(entry CampaignACL) error
+
+	// GetGlobalRole returns the role subject holds on actions that
+	// aren't scoped to a single campaign (creating one, listing every
+	// one). It returns ("", nil), not an error, when subject has no
+	// explicit grant; pkg/authz treats that as RoleViewer.
+	GetGlobalRole(subject string) (Role, error)
+
+	// InsertAuditLog and ListAuditLog record and retrieve the
+	// append-only audit trail exposed via the /audit endpoint.
+	InsertAuditLog(entry AuditLogEntry) error
+	ListAuditLog(q Query) ([]AuditLogEntry, error)
+
+	// InsertWebhookDeadLetter records a webhook delivery that exhausted
+	// its retry budget, for operator inspection and redelivery.
+	InsertWebhookDeadLetter(entry WebhookDeadLetter) error
+}
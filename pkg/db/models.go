@@ -0,0 +1,95 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CampaignStatus tracks where a campaign is in its lifecycle.
+type CampaignStatus string
+
+const (
+	StatusPending   CampaignStatus = "pending"
+	StatusRunning   CampaignStatus = "running"
+	StatusPaused    CampaignStatus = "paused"
+	StatusCompleted CampaignStatus = "completed"
+	StatusCanceled  CampaignStatus = "canceled"
+	StatusError     CampaignStatus = "error"
+)
+
+// Campaign is a single password spraying run: a set of users and
+// passwords to try against a provider, on a schedule.
+type Campaign struct {
+	gorm.Model
+
+	Name     string
+	Status   CampaignStatus
+	Provider string
+
+	Users     []string `gorm:"serializer:json"`
+	Passwords []string `gorm:"serializer:json"`
+
+	// ProviderConfig carries provider-specific settings (API endpoints,
+	// tenant IDs, credentials). See pkg/secrets for how credential
+	// fields within it are represented once they're no longer stored
+	// in plaintext.
+	ProviderConfig map[string]interface{} `gorm:"serializer:json"`
+
+	// Schedule shapes how buckets of the user/password product are
+	// dispatched over time, e.g. one attempt per user every 30 minutes.
+	ScheduleInterval time.Duration
+
+	// WebhookURL, if set, receives HMAC-signed POSTs on result.found,
+	// campaign.completed, and campaign.error. WebhookSecret signs the
+	// payloads; WebhookEvents restricts which of those are sent,
+	// defaulting to all of them when empty.
+	WebhookURL    string
+	WebhookSecret string
+	WebhookEvents []string `gorm:"serializer:json"`
+}
+
+// Result is a single spray attempt outcome.
+type Result struct {
+	gorm.Model
+
+	CampaignID uint
+	Username   string
+	Password   string
+	Success    bool
+}
+
+// WebhookDeadLetter is a webhook delivery that exhausted its retry
+// budget; operators can inspect and manually redeliver these.
+type WebhookDeadLetter struct {
+	gorm.Model
+
+	CampaignID uint
+	Event      string
+	Payload    []byte
+	LastError  string
+	Attempts   int
+}
+
+// Query is a user-supplied filter used by the results and describe
+// endpoints: Fields limits which columns are returned and CampaignID
+// scopes the query to a single campaign.
+type Query struct {
+	CampaignID uint
+	Fields     []string
+	Filter     string
+}
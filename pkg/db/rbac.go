@@ -0,0 +1,65 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import "gorm.io/gorm"
+
+// Role is a named permission level an operator can hold globally or on
+// a specific campaign. See pkg/authz for how roles are turned into
+// allow/deny decisions.
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleOperator Role = "operator"
+	RoleViewer   Role = "viewer"
+)
+
+// CampaignACL grants a subject (an auth.Identity.Subject) a role on a
+// campaign. A campaign's creator is granted RoleAdmin automatically;
+// additional grants are managed via the /campaign/acl endpoint.
+type CampaignACL struct {
+	gorm.Model
+
+	CampaignID uint
+	Subject    string
+	Role       Role
+}
+
+// GlobalRole grants a subject a role that applies to actions not scoped
+// to any one campaign (e.g. creating a campaign, listing every
+// campaign). A subject with no GlobalRole row defaults to RoleViewer,
+// so authentication alone never implies the ability to create or list
+// campaigns; an admin must grant RoleOperator or RoleAdmin explicitly.
+type GlobalRole struct {
+	gorm.Model
+
+	Subject string
+	Role    Role
+}
+
+// AuditLogEntry is one append-only record of an operator action against
+// the orchestrator, written both to the database and to a JSON-lines
+// file sink for offline/SIEM ingestion.
+type AuditLogEntry struct {
+	gorm.Model
+
+	RequestID  string
+	Subject    string
+	SourceIP   string
+	Action     string
+	CampaignID uint
+	Detail     string
+}
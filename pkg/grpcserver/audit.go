@@ -0,0 +1,88 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/praetorian-inc/trident/pkg/authz"
+	"github.com/praetorian-inc/trident/pkg/db"
+)
+
+// audit records one RPC to the audit log: who made it, against which
+// campaign, and whether it was ultimately allowed. Every RPC below calls
+// this after its pkg/service call returns, regardless of outcome, so
+// the trail covers denials as well as successes and matches pkg/server's
+// HTTP handlers entry for entry.
+func (s *Server) audit(ctx context.Context, action authz.Action, campaignID uint, err error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	requestID, sourceIP := auditFields(ctx)
+	s.Audit.Record(db.AuditLogEntry{
+		RequestID:  requestID,
+		Subject:    identity.Subject,
+		SourceIP:   sourceIP,
+		Action:     string(action),
+		CampaignID: campaignID,
+		Detail:     denialDetail(err),
+	})
+}
+
+func denialDetail(err error) string {
+	if err == nil {
+		return "allowed"
+	}
+	return err.Error()
+}
+
+// auditFields extracts the call metadata every audit entry records: a
+// request ID, read from the "x-request-id" metadata key set by
+// trident-cli or grpc-gateway, and the caller's source IP. Behind the
+// Cloudflare Access/Tunnel deployment (see pkg/auth/cloudflare), the
+// peer address on ctx is cloudflared's own, not the operator's, so
+// "cf-connecting-ip" (and, failing that, the first hop of a generic
+// "x-forwarded-for") is preferred when grpc-gateway has forwarded it.
+func auditFields(ctx context.Context) (requestID, sourceIP string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID = firstMetadataValue(md, "x-request-id")
+
+	if ip := firstMetadataValue(md, "cf-connecting-ip"); ip != "" {
+		return requestID, ip
+	}
+	if fwd := firstMetadataValue(md, "x-forwarded-for"); fwd != "" {
+		return requestID, strings.TrimSpace(strings.Split(fwd, ",")[0])
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		sourceIP = p.Addr.String()
+	}
+
+	return requestID, sourceIP
+}
+
+func firstMetadataValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/praetorian-inc/trident/pkg/auth"
+	"github.com/praetorian-inc/trident/pkg/server"
+)
+
+var tracer = otel.Tracer("github.com/praetorian-inc/trident/pkg/grpcserver")
+
+// identityContextKey is the context key under which the unary and stream
+// interceptors below store the authenticated auth.Identity.
+type identityContextKey struct{}
+
+// authenticator validates the bearer token carried in a call's metadata
+// and is shared by UnaryServerInterceptor and StreamServerInterceptor.
+// It reuses pkg/server.OIDCAuthConfig so the gRPC and HTTP transports are
+// configured identically.
+type authenticator struct {
+	verifier     *gooidc.IDTokenVerifier
+	subjectClaim string
+	emailClaim   string
+}
+
+func newAuthenticator(ctx context.Context, cfg server.OIDCAuthConfig) (*authenticator, error) {
+	provider, err := gooidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectClaim := cfg.SubjectClaim
+	if subjectClaim == "" {
+		subjectClaim = "sub"
+	}
+	emailClaim := cfg.EmailClaim
+	if emailClaim == "" {
+		emailClaim = "email"
+	}
+
+	return &authenticator{
+		verifier:     provider.Verifier(&gooidc.Config{ClientID: cfg.Audience}),
+		subjectClaim: subjectClaim,
+		emailClaim:   emailClaim,
+	}, nil
+}
+
+// authenticate validates the bearer token on ctx's incoming gRPC
+// metadata and, if present, extracts a W3C trace context from the same
+// metadata so the RPC's span continues whatever trace trident-cli (or
+// grpc-gateway) started.
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, mdCarrier(md))
+
+	rawToken, ok := bearerToken(md)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	idToken, err := a.verifier.Verify(ctx, rawToken)
+	if err != nil {
+		log.WithError(err).Warn("rejected grpc call with invalid id token")
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		log.WithError(err).Error("failed to parse id token claims")
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	identity := auth.Identity{
+		Subject: stringClaim(claims, a.subjectClaim),
+		Email:   stringClaim(claims, a.emailClaim),
+		Claims:  claims,
+	}
+
+	return context.WithValue(ctx, identityContextKey{}, identity), nil
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// validates every call's bearer token against cfg's OIDC provider and
+// attaches the resulting auth.Identity to the handler's context.
+func UnaryServerInterceptor(ctx context.Context, cfg server.OIDCAuthConfig) (grpc.UnaryServerInterceptor, error) {
+	a, err := newAuthenticator(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		authed, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		authed, span := tracer.Start(authed, "grpc."+info.FullMethod)
+		defer span.End()
+
+		return handler(authed, req)
+	}, nil
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor's equivalent for
+// StreamResults, trident's one server-streaming RPC.
+func StreamServerInterceptor(ctx context.Context, cfg server.OIDCAuthConfig) (grpc.StreamServerInterceptor, error) {
+	a, err := newAuthenticator(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		authed, err := a.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		authed, span := tracer.Start(authed, "grpc."+info.FullMethod)
+		defer span.End()
+
+		return handler(srv, &authedStream{ServerStream: ss, ctx: authed})
+	}, nil
+}
+
+// authedStream overrides grpc.ServerStream.Context so handlers see the
+// context carrying the identity attached by authenticate.
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authedStream) Context() context.Context { return s.ctx }
+
+// IdentityFromContext returns the auth.Identity attached by
+// UnaryServerInterceptor or StreamServerInterceptor, if any.
+func IdentityFromContext(ctx context.Context) (auth.Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(auth.Identity)
+	return identity, ok
+}
+
+// mdCarrier adapts grpc metadata.MD to propagation.TextMapCarrier so the
+// OpenTelemetry propagator can read a traceparent header out of it.
+type mdCarrier metadata.MD
+
+func (c mdCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c mdCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c mdCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func bearerToken(md metadata.MD) (string, bool) {
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	const prefix = "Bearer "
+	if !strings.HasPrefix(values[0], prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(values[0], prefix), true
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+	v, _ := claims[name].(string)
+	return v
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcserver
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	tridentv1 "github.com/praetorian-inc/trident/api/proto/trident/v1"
+	"github.com/praetorian-inc/trident/pkg/db"
+)
+
+func toProtoCampaign(c db.Campaign) (*tridentv1.Campaign, error) {
+	providerConfig, err := structpb.NewStruct(c.ProviderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("converting provider config: %w", err)
+	}
+
+	return &tridentv1.Campaign{
+		Id:                      uint32(c.ID),
+		Name:                    c.Name,
+		Status:                  string(c.Status),
+		Provider:                c.Provider,
+		Users:                   c.Users,
+		Passwords:               c.Passwords,
+		ScheduleIntervalSeconds: int64(c.ScheduleInterval / time.Second),
+		ProviderConfig:          providerConfig,
+		WebhookUrl:              c.WebhookURL,
+		WebhookSecret:           c.WebhookSecret,
+		WebhookEvents:           c.WebhookEvents,
+	}, nil
+}
+
+func fromProtoCampaign(c *tridentv1.Campaign) db.Campaign {
+	return db.Campaign{
+		Name:             c.Name,
+		Status:           db.CampaignStatus(c.Status),
+		Provider:         c.Provider,
+		Users:            c.Users,
+		Passwords:        c.Passwords,
+		ScheduleInterval: time.Duration(c.ScheduleIntervalSeconds) * time.Second,
+		ProviderConfig:   c.ProviderConfig.AsMap(),
+		WebhookURL:       c.WebhookUrl,
+		WebhookSecret:    c.WebhookSecret,
+		WebhookEvents:    c.WebhookEvents,
+	}
+}
+
+func toProtoResult(r db.Result) *tridentv1.Result {
+	return &tridentv1.Result{
+		Id:         uint32(r.ID),
+		CampaignId: uint32(r.CampaignID),
+		Username:   r.Username,
+		Password:   r.Password,
+		Success:    r.Success,
+	}
+}
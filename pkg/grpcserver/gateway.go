@@ -0,0 +1,41 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+
+	tridentv1 "github.com/praetorian-inc/trident/api/proto/trident/v1"
+)
+
+// NewGatewayMux returns an http.Handler that translates REST requests
+// matching trident.proto's google.api.http annotations into in-process
+// calls against srv, so existing HTTP clients keep working even when an
+// operator runs trident-orchestrator with the gRPC transport only. It
+// calls srv directly rather than dialing back over the network, since
+// both live in the same process.
+func NewGatewayMux(ctx context.Context, srv *Server) (http.Handler, error) {
+	mux := runtime.NewServeMux()
+
+	if err := tridentv1.RegisterTridentServiceHandlerServer(ctx, mux, srv); err != nil {
+		return nil, fmt.Errorf("registering grpc-gateway handler: %w", err)
+	}
+
+	return mux, nil
+}
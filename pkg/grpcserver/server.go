@@ -0,0 +1,176 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcserver is trident's gRPC transport: it implements the
+// TridentService defined in api/proto/trident/v1 by adapting calls to
+// pkg/service, the same layer pkg/server's HTTP handlers use, so the
+// two transports never drift in what they allow.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tridentv1 "github.com/praetorian-inc/trident/api/proto/trident/v1"
+	"github.com/praetorian-inc/trident/pkg/authz"
+	"github.com/praetorian-inc/trident/pkg/db"
+	"github.com/praetorian-inc/trident/pkg/server"
+	"github.com/praetorian-inc/trident/pkg/service"
+)
+
+// Server adapts pkg/service.Service to tridentv1.TridentServiceServer.
+type Server struct {
+	tridentv1.UnimplementedTridentServiceServer
+
+	Service *service.Service
+
+	// Audit records every RPC below to the same audit log pkg/server's
+	// HTTP handlers write to, so an operator's trail is complete
+	// regardless of which transport they used.
+	Audit *server.AuditSink
+}
+
+// New returns a Server that dispatches every RPC to svc, auditing each
+// one to audit.
+func New(svc *service.Service, audit *server.AuditSink) *Server {
+	return &Server{Service: svc, Audit: audit}
+}
+
+func (s *Server) CreateCampaign(ctx context.Context, req *tridentv1.CreateCampaignRequest) (*tridentv1.Campaign, error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	created, err := s.Service.CreateCampaign(ctx, identity, fromProtoCampaign(req.Campaign))
+	s.audit(ctx, authz.ActionCreateCampaign, created.ID, err)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoCampaign(created)
+}
+
+func (s *Server) ListCampaigns(ctx context.Context, req *tridentv1.ListCampaignsRequest) (*tridentv1.ListCampaignsResponse, error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	campaigns, err := s.Service.ListCampaigns(ctx, identity)
+	s.audit(ctx, authz.ActionReadCampaign, 0, err)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &tridentv1.ListCampaignsResponse{Campaigns: make([]*tridentv1.Campaign, len(campaigns))}
+	for i, c := range campaigns {
+		pc, err := toProtoCampaign(c)
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+		resp.Campaigns[i] = pc
+	}
+
+	return resp, nil
+}
+
+func (s *Server) DescribeCampaign(ctx context.Context, req *tridentv1.DescribeCampaignRequest) (*tridentv1.Campaign, error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	campaign, err := s.Service.DescribeCampaign(ctx, identity, db.Query{CampaignID: uint(req.CampaignId)})
+	s.audit(ctx, authz.ActionReadCampaign, uint(req.CampaignId), err)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoCampaign(campaign)
+}
+
+func (s *Server) UpdateStatus(ctx context.Context, req *tridentv1.UpdateStatusRequest) (*tridentv1.Campaign, error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	campaign, err := s.Service.UpdateStatus(ctx, identity, uint(req.CampaignId), db.CampaignStatus(req.Status))
+	s.audit(ctx, authz.ActionMutateCampaign, uint(req.CampaignId), err)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoCampaign(campaign)
+}
+
+func (s *Server) QueryResults(ctx context.Context, req *tridentv1.QueryResultsRequest) (*tridentv1.QueryResultsResponse, error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	results, err := s.Service.QueryResults(ctx, identity, db.Query{
+		CampaignID: uint(req.CampaignId),
+		Fields:     req.Fields,
+		Filter:     req.Filter,
+	})
+	s.audit(ctx, authz.ActionReadResults, uint(req.CampaignId), err)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &tridentv1.QueryResultsResponse{Results: make([]*tridentv1.Result, len(results))}
+	for i, r := range results {
+		resp.Results[i] = toProtoResult(r)
+	}
+
+	return resp, nil
+}
+
+// StreamResults tails a campaign's results over a server-streaming RPC;
+// it has no REST analogue, unlike the rest of TridentService, which is
+// why pkg/server separately exposes GET /results/stream over SSE.
+func (s *Server) StreamResults(req *tridentv1.StreamResultsRequest, stream tridentv1.TridentService_StreamResultsServer) error {
+	ctx := stream.Context()
+
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	err := s.Service.StreamResults(ctx, identity, uint(req.CampaignId), func(result db.Result) error {
+		return stream.Send(toProtoResult(result))
+	})
+	s.audit(ctx, authz.ActionReadResults, uint(req.CampaignId), err)
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	return nil
+}
+
+// toStatusError maps an error returned by pkg/service to the gRPC status
+// a caller would expect: PermissionDenied when authz.Check denied the
+// request, Internal for everything else.
+func toStatusError(err error) error {
+	if errors.Is(err, authz.ErrForbidden) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
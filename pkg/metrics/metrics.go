@@ -0,0 +1,80 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics holds the Prometheus collectors shared across
+// trident's packages, so pkg/server's HTTP middleware, pkg/service's
+// campaign lifecycle, and pkg/scheduler's dispatch loop all publish to
+// the same registry under one set of names.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// HTTPRequestsTotal counts every request pkg/server.Instrument
+	// wraps, labeled by handler name and response status code.
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "trident_http_requests_total",
+		Help: "Total HTTP requests handled, labeled by handler and status code.",
+	}, []string{"handler", "code"})
+
+	// CampaignCreatedTotal counts campaigns accepted by pkg/service.
+	CampaignCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "trident_campaign_created_total",
+		Help: "Total campaigns created.",
+	})
+
+	// SchedulerDispatchSeconds measures how long the scheduler handoff
+	// for one campaign takes, from pkg/service.CreateCampaign's call to
+	// Scheduler.Schedule until it returns.
+	SchedulerDispatchSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "trident_scheduler_dispatch_seconds",
+		Help:    "Time taken to hand a campaign off to the scheduler.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// ResultsTotal counts spray attempt results as they land on the
+	// scheduler's pub/sub bus, labeled "success" or "failure".
+	ResultsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "trident_results_total",
+		Help: "Total spray attempt results recorded, labeled by outcome.",
+	}, []string{"outcome"})
+
+	// ActiveCampaigns tracks campaigns currently handed off to the
+	// scheduler (i.e. not yet canceled).
+	ActiveCampaigns = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "trident_active_campaigns",
+		Help: "Campaigns currently scheduled for dispatch.",
+	})
+
+	// QueuedBuckets tracks dispatch buckets waiting to be sent across
+	// all campaigns. Scheduler implementations should keep it current
+	// as they enqueue and dispatch buckets.
+	QueuedBuckets = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "trident_queued_buckets",
+		Help: "Dispatch buckets waiting to be sent across all campaigns.",
+	})
+)
+
+// Handler serves the Prometheus text exposition format for every
+// collector above. Callers register it at GET /metrics directly,
+// without wrapping it in pkg/server.Instrument.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parse provides strict JSON request body decoding for trident's
+// HTTP handlers, turning malformed input into client-facing errors
+// instead of generic 500s.
+package parse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MalformedRequest is returned by DecodeJSONBody when the request body
+// fails to parse for a reason the caller is responsible for (as opposed
+// to a server-side encoding bug), along with the HTTP status and message
+// that should be sent back to the client.
+type MalformedRequest struct {
+	Status int
+	Msg    string
+}
+
+func (e *MalformedRequest) Error() string {
+	return e.Msg
+}
+
+// DecodeJSONBody decodes r's body into dst, rejecting unknown fields and
+// bodies containing more than one JSON value. Errors are wrapped as
+// *MalformedRequest so handlers can translate them directly into an
+// http.Error call.
+func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "application/json") {
+		return &MalformedRequest{Status: http.StatusUnsupportedMediaType, Msg: "Content-Type header is not application/json"}
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var syntaxError *json.SyntaxError
+		var unmarshalTypeError *json.UnmarshalTypeError
+
+		switch {
+		case errors.As(err, &syntaxError):
+			return &MalformedRequest{Status: http.StatusBadRequest, Msg: fmt.Sprintf("request body contains badly-formed JSON at position %d", syntaxError.Offset)}
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return &MalformedRequest{Status: http.StatusBadRequest, Msg: "request body contains badly-formed JSON"}
+		case errors.As(err, &unmarshalTypeError):
+			return &MalformedRequest{Status: http.StatusBadRequest, Msg: fmt.Sprintf("request body contains an invalid value for field %q at position %d", unmarshalTypeError.Field, unmarshalTypeError.Offset)}
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+			return &MalformedRequest{Status: http.StatusBadRequest, Msg: fmt.Sprintf("request body contains unknown field %s", field)}
+		case errors.Is(err, io.EOF):
+			return &MalformedRequest{Status: http.StatusBadRequest, Msg: "request body must not be empty"}
+		default:
+			return err
+		}
+	}
+
+	if dec.More() {
+		return &MalformedRequest{Status: http.StatusBadRequest, Msg: "request body must only contain a single JSON value"}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/praetorian-inc/trident/pkg/db"
+	"github.com/praetorian-inc/trident/pkg/metrics"
+)
+
+// resultBus is an in-process pub/sub bus that a Scheduler implementation
+// publishes results onto as they land, so handlers can tail a campaign
+// without polling the database. It is safe for concurrent use.
+type resultBus struct {
+	mu   sync.Mutex
+	subs map[uint]map[chan db.Result]struct{}
+}
+
+func newResultBus() *resultBus {
+	return &resultBus{subs: make(map[uint]map[chan db.Result]struct{})}
+}
+
+// Subscribe implements the Subscribe method of Scheduler for embedders.
+func (b *resultBus) Subscribe(campaignID uint) (<-chan db.Result, func()) {
+	ch := make(chan db.Result, 16)
+
+	b.mu.Lock()
+	if b.subs[campaignID] == nil {
+		b.subs[campaignID] = make(map[chan db.Result]struct{})
+	}
+	b.subs[campaignID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		delete(b.subs[campaignID], ch)
+		if len(b.subs[campaignID]) == 0 {
+			delete(b.subs, campaignID)
+		}
+		b.mu.Unlock()
+		close(ch)
+	}
+
+	return ch, unsubscribe
+}
+
+// publish fans result out to every current subscriber of its campaign.
+// Slow subscribers are dropped rather than blocking dispatch; results
+// remain durably recorded in the database regardless.
+func (b *resultBus) publish(result db.Result) {
+	outcome := "failure"
+	if result.Success {
+		outcome = "success"
+	}
+	metrics.ResultsTotal.WithLabelValues(outcome).Inc()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[result.CampaignID] {
+		select {
+		case ch <- result:
+		default:
+			log.Warnf("dropping result for campaign id=%d: subscriber is not keeping up", result.CampaignID)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scheduler dispatches campaigns' user/password buckets to
+// provider workers over time, according to each campaign's schedule.
+package scheduler
+
+import "github.com/praetorian-inc/trident/pkg/db"
+
+// Scheduler takes ownership of a campaign's dispatch once it has been
+// persisted, doling out login attempts to provider workers over time.
+type Scheduler interface {
+	// Schedule begins dispatching c's buckets. It returns once the
+	// campaign has been accepted for scheduling, not once it completes.
+	// c.ProviderConfig carries an opaque secrets.SecretStore reference
+	// rather than a raw credential; implementations resolve it via
+	// SecretStore.Get at dispatch time, not before, so a rotated
+	// credential takes effect without re-scheduling the campaign.
+	// Implementations should keep pkg/metrics.QueuedBuckets current as
+	// buckets are enqueued and dispatched, so the gauge reflects every
+	// campaign's backlog, not just the one most recently scheduled.
+	Schedule(c db.Campaign) error
+
+	// Pause stops dispatching new buckets for the given campaign
+	// without losing its place; Resume picks back up where it left
+	// off. Both are no-ops if the campaign isn't currently scheduled.
+	Pause(id uint) error
+	Resume(id uint) error
+
+	// Cancel stops dispatching the campaign for good; any in-flight
+	// buckets are allowed to finish but no new ones are handed out.
+	Cancel(id uint) error
+
+	// Subscribe registers for every db.Result landed by the given
+	// campaign from this point forward, for as long as the returned
+	// unsubscribe function hasn't been called. The channel is closed
+	// after unsubscribe runs, never before.
+	Subscribe(campaignID uint) (results <-chan db.Result, unsubscribe func())
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package local implements secrets.SecretStore as an AES-GCM encrypted
+// column in trident's own database, for deployments that don't run a
+// Vault cluster. Every value is encrypted with a per-secret random
+// nonce under a single key-encryption-key (KEK) supplied at startup.
+package local
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// refPrefix is prepended to every reference this store hands out, so
+// pkg/secrets callers can route Get/Rotate/Delete to the right backend
+// without maintaining their own registry.
+const refPrefix = "local://"
+
+// secretRow is the encrypted-at-rest representation of one secret.
+type secretRow struct {
+	gorm.Model
+
+	ExternalID string `gorm:"uniqueIndex"`
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+func (secretRow) TableName() string { return "local_secrets" }
+
+// Store is a secrets.SecretStore backed by a database table encrypted
+// under a single AES-256 KEK.
+type Store struct {
+	db  *gorm.DB
+	gcm cipher.AEAD
+}
+
+// New returns a Store that encrypts/decrypts with kek (which must be
+// exactly 32 bytes, e.g. read from KMS or a file on disk) and persists
+// ciphertext via db. It migrates its own table on first use.
+func New(db *gorm.DB, kek []byte) (*Store, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("constructing AES cipher from KEK: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("constructing AES-GCM: %w", err)
+	}
+
+	if err := db.AutoMigrate(&secretRow{}); err != nil {
+		return nil, fmt.Errorf("migrating local secrets table: %w", err)
+	}
+
+	return &Store{db: db, gcm: gcm}, nil
+}
+
+// Put encrypts value under a fresh random nonce and returns its ref.
+func (s *Store) Put(ctx context.Context, value string) (string, error) {
+	id := uuid.NewString()
+
+	nonce, ciphertext, err := s.seal(value)
+	if err != nil {
+		return "", err
+	}
+
+	row := secretRow{ExternalID: id, Nonce: nonce, Ciphertext: ciphertext}
+	if err := s.db.WithContext(ctx).Create(&row).Error; err != nil {
+		return "", fmt.Errorf("storing secret: %w", err)
+	}
+
+	return refPrefix + id, nil
+}
+
+// Get decrypts and returns the value at ref.
+func (s *Store) Get(ctx context.Context, ref string) (string, error) {
+	id, err := externalID(ref)
+	if err != nil {
+		return "", err
+	}
+
+	var row secretRow
+	if err := s.db.WithContext(ctx).Where("external_id = ?", id).First(&row).Error; err != nil {
+		return "", fmt.Errorf("loading secret %s: %w", ref, err)
+	}
+
+	return s.open(row.Nonce, row.Ciphertext)
+}
+
+// Rotate re-encrypts value in place under ref, keeping its external ID
+// (and therefore every campaign reference to it) unchanged.
+func (s *Store) Rotate(ctx context.Context, ref string, value string) error {
+	id, err := externalID(ref)
+	if err != nil {
+		return err
+	}
+
+	nonce, ciphertext, err := s.seal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Model(&secretRow{}).
+		Where("external_id = ?", id).
+		Updates(map[string]interface{}{"nonce": nonce, "ciphertext": ciphertext}).Error
+}
+
+// Delete removes the secret at ref.
+func (s *Store) Delete(ctx context.Context, ref string) error {
+	id, err := externalID(ref)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Where("external_id = ?", id).Delete(&secretRow{}).Error
+}
+
+func (s *Store) seal(value string) (nonce, ciphertext []byte, err error) {
+	nonce = make([]byte, s.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	return nonce, s.gcm.Seal(nil, nonce, []byte(value), nil), nil
+}
+
+func (s *Store) open(nonce, ciphertext []byte) (string, error) {
+	plaintext, err := s.gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting secret: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func externalID(ref string) (string, error) {
+	if !strings.HasPrefix(ref, refPrefix) {
+		return "", fmt.Errorf("ref %q is not a local:// secret reference", ref)
+	}
+	return strings.TrimPrefix(ref, refPrefix), nil
+}
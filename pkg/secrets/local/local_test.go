@@ -0,0 +1,120 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func newGCM(t *testing.T, kek []byte) cipher.AEAD {
+	t.Helper()
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		t.Fatalf("aes.NewCipher() error = %v", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("cipher.NewGCM() error = %v", err)
+	}
+
+	return gcm
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	s := &Store{gcm: newGCM(t, bytes.Repeat([]byte{0x42}, 32))}
+
+	nonce, ciphertext, err := s.seal("super secret value")
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+	if bytes.Equal(ciphertext, []byte("super secret value")) {
+		t.Fatal("seal() stored the plaintext verbatim")
+	}
+
+	got, err := s.open(nonce, ciphertext)
+	if err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+	if got != "super secret value" {
+		t.Fatalf("open() = %q, want %q", got, "super secret value")
+	}
+}
+
+func TestSealUsesAFreshNonceEachTime(t *testing.T) {
+	s := &Store{gcm: newGCM(t, bytes.Repeat([]byte{0x42}, 32))}
+
+	nonce1, ciphertext1, err := s.seal("same value")
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+	nonce2, ciphertext2, err := s.seal("same value")
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+
+	if bytes.Equal(nonce1, nonce2) {
+		t.Fatal("seal() reused a nonce across calls, which breaks AES-GCM's confidentiality guarantee")
+	}
+	if bytes.Equal(ciphertext1, ciphertext2) {
+		t.Fatal("seal() produced identical ciphertext for identical plaintext, implying a reused nonce")
+	}
+}
+
+func TestOpenRejectsWrongKey(t *testing.T) {
+	sealer := &Store{gcm: newGCM(t, bytes.Repeat([]byte{0x42}, 32))}
+	opener := &Store{gcm: newGCM(t, bytes.Repeat([]byte{0x24}, 32))}
+
+	nonce, ciphertext, err := sealer.seal("super secret value")
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+
+	if _, err := opener.open(nonce, ciphertext); err == nil {
+		t.Fatal("open() succeeded decrypting under the wrong key")
+	}
+}
+
+func TestOpenRejectsTamperedCiphertext(t *testing.T) {
+	s := &Store{gcm: newGCM(t, bytes.Repeat([]byte{0x42}, 32))}
+
+	nonce, ciphertext, err := s.seal("super secret value")
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+	ciphertext[0] ^= 0xFF
+
+	if _, err := s.open(nonce, ciphertext); err == nil {
+		t.Fatal("open() succeeded on tampered ciphertext, defeating AES-GCM's authentication")
+	}
+}
+
+func TestExternalID(t *testing.T) {
+	id, err := externalID("local://abc-123")
+	if err != nil {
+		t.Fatalf("externalID() error = %v", err)
+	}
+	if id != "abc-123" {
+		t.Fatalf("externalID() = %q, want %q", id, "abc-123")
+	}
+
+	if _, err := externalID("vault://abc-123"); err == nil {
+		t.Fatal("externalID() accepted a ref from a different backend")
+	}
+}
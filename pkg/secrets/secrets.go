@@ -0,0 +1,46 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets abstracts where provider credentials (Okta API
+// tokens, O365 client secrets, ...) actually live, so that campaigns
+// can carry an opaque reference instead of a plaintext value.
+// Implementations live in subpackages, one per backend.
+package secrets
+
+import "context"
+
+// CredentialKey is the well-known field within a db.Campaign's
+// ProviderConfig that carries the provider credential: a raw value on
+// the way in over CampaignHandler, and an opaque SecretStore ref
+// (e.g. "vault://path" or "local://uuid") once stored.
+const CredentialKey = "credential"
+
+// SecretStore puts, retrieves, rotates, and deletes secret values,
+// addressing them by an opaque reference string of the implementation's
+// own choosing (e.g. "local://<uuid>" or "vault://<path>#<key>").
+type SecretStore interface {
+	// Get returns the current plaintext value for ref.
+	Get(ctx context.Context, ref string) (string, error)
+
+	// Put stores value and returns a new reference for it.
+	Put(ctx context.Context, value string) (ref string, err error)
+
+	// Delete removes the secret at ref. It is not an error to delete a
+	// ref that doesn't exist.
+	Delete(ctx context.Context, ref string) error
+
+	// Rotate replaces the value at ref in place, keeping the same
+	// reference so campaigns that already carry it keep working.
+	Rotate(ctx context.Context, ref string, value string) error
+}
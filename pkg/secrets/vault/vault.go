@@ -0,0 +1,191 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vault implements secrets.SecretStore against a HashiCorp
+// Vault KV v2 secrets engine, authenticating via AppRole and renewing
+// its own lease in the background.
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/google/uuid"
+)
+
+// refPrefix is prepended to every reference this store hands out.
+const refPrefix = "vault://"
+
+// valueKey is the single field name used within each KV v2 secret;
+// trident doesn't need multi-key secrets, so every entry is
+// {"value": "<secret>"}.
+const valueKey = "value"
+
+// Config describes how to reach and authenticate against Vault.
+type Config struct {
+	// Address is the Vault server's base URL.
+	Address string
+
+	// Mount is the KV v2 secrets engine mount point, e.g. "secret".
+	Mount string
+
+	// PathPrefix namespaces every secret trident writes, e.g.
+	// "trident/provider-credentials".
+	PathPrefix string
+
+	// RoleID and SecretID authenticate via the AppRole auth method.
+	RoleID   string
+	SecretID string
+
+	// RenewInterval controls how often the leased token is renewed;
+	// it should be comfortably shorter than the token's TTL.
+	RenewInterval time.Duration
+}
+
+// Store is a secrets.SecretStore backed by Vault KV v2.
+type Store struct {
+	cfg    Config
+	client *vaultapi.Client
+}
+
+// New logs into Vault via AppRole and starts a background goroutine
+// that renews the resulting token every cfg.RenewInterval until ctx is
+// canceled.
+func New(ctx context.Context, cfg Config) (*Store, error) {
+	client, err := vaultapi.NewClient(&vaultapi.Config{Address: cfg.Address})
+	if err != nil {
+		return nil, fmt.Errorf("constructing vault client: %w", err)
+	}
+
+	s := &Store{cfg: cfg, client: client}
+
+	if err := s.login(ctx); err != nil {
+		return nil, fmt.Errorf("authenticating to vault via approle: %w", err)
+	}
+
+	go s.renewLoop(ctx)
+
+	return s, nil
+}
+
+func (s *Store) login(ctx context.Context) error {
+	secret, err := s.client.Logical().WriteWithContext(ctx, "auth/approle/login", map[string]interface{}{
+		"role_id":   s.cfg.RoleID,
+		"secret_id": s.cfg.SecretID,
+	})
+	if err != nil {
+		return err
+	}
+	if secret == nil || secret.Auth == nil {
+		return fmt.Errorf("approle login returned no auth info")
+	}
+
+	s.client.SetToken(secret.Auth.ClientToken)
+	return nil
+}
+
+// renewLoop keeps the AppRole lease alive so long-running orchestrator
+// processes don't have their Vault access silently expire. A failed
+// renewal falls back to a fresh login, since AppRole logins are cheap
+// and idempotent.
+func (s *Store) renewLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.client.Auth().Token().RenewSelfWithContext(ctx, 0); err != nil {
+				log.WithError(err).Warn("vault token renewal failed, re-authenticating via approle")
+				if err := s.login(ctx); err != nil {
+					log.WithError(err).Error("vault re-authentication failed")
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Put writes value to a newly generated path and returns its ref.
+func (s *Store) Put(ctx context.Context, value string) (string, error) {
+	id := uuid.NewString()
+	path := s.secretPath(id)
+
+	if _, err := s.client.KVv2(s.cfg.Mount).Put(ctx, path, map[string]interface{}{valueKey: value}); err != nil {
+		return "", fmt.Errorf("writing secret to vault: %w", err)
+	}
+
+	return refPrefix + path, nil
+}
+
+// Get reads the current value at ref, always hitting Vault directly so
+// rotated secrets are picked up on the next dispatch without a restart.
+func (s *Store) Get(ctx context.Context, ref string) (string, error) {
+	path, err := vaultPath(ref)
+	if err != nil {
+		return "", err
+	}
+
+	kv, err := s.client.KVv2(s.cfg.Mount).Get(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("reading secret from vault: %w", err)
+	}
+
+	value, ok := kv.Data[valueKey].(string)
+	if !ok {
+		return "", fmt.Errorf("secret at %s has no %q field", path, valueKey)
+	}
+
+	return value, nil
+}
+
+// Rotate writes a new version of the secret at ref; Vault KV v2 keeps
+// prior versions, which Get never returns but which remain available
+// for operator-driven rollback via the Vault CLI.
+func (s *Store) Rotate(ctx context.Context, ref string, value string) error {
+	path, err := vaultPath(ref)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.KVv2(s.cfg.Mount).Put(ctx, path, map[string]interface{}{valueKey: value})
+	return err
+}
+
+// Delete permanently destroys every version of the secret at ref.
+func (s *Store) Delete(ctx context.Context, ref string) error {
+	path, err := vaultPath(ref)
+	if err != nil {
+		return err
+	}
+
+	return s.client.KVv2(s.cfg.Mount).DeleteMetadata(ctx, path)
+}
+
+func (s *Store) secretPath(id string) string {
+	return strings.TrimSuffix(s.cfg.PathPrefix, "/") + "/" + id
+}
+
+func vaultPath(ref string) (string, error) {
+	if !strings.HasPrefix(ref, refPrefix) {
+		return "", fmt.Errorf("ref %q is not a vault:// secret reference", ref)
+	}
+	return strings.TrimPrefix(ref, refPrefix), nil
+}
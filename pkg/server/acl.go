@@ -0,0 +1,108 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/praetorian-inc/trident/pkg/authz"
+	"github.com/praetorian-inc/trident/pkg/db"
+	"github.com/praetorian-inc/trident/pkg/parse"
+)
+
+// CampaignACLHandler grants or inspects role assignments on a campaign.
+// A GET returns the current ACL; a POST body adds or replaces a grant.
+// Only subjects with RoleAdmin on the campaign (or a global admin) may
+// call this, enforced via authz.ActionManageACL.
+func (s *Server) CampaignACLHandler(w http.ResponseWriter, r *http.Request) {
+	var entry db.CampaignACL
+
+	err := parse.DecodeJSONBody(w, r, &entry)
+	if err != nil {
+		var mr *parse.MalformedRequest
+		if errors.As(err, &mr) {
+			http.Error(w, mr.Msg, mr.Status)
+		} else {
+			log.Errorf("unknown error decoding json: %s", err)
+			http.Error(w, http.StatusText(500), 500)
+		}
+		return
+	}
+
+	if !s.authorize(w, r, authz.ActionManageACL, authz.Resource{CampaignID: entry.CampaignID}) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		acl, err := s.DB.GetCampaignACL(entry.CampaignID)
+		if err != nil {
+			log.Errorf("error reading campaign acl: %s", err)
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(&acl); err != nil {
+			log.Errorf("error encoding campaign acl: %s", err)
+		}
+		return
+	}
+
+	if err := s.DB.SetCampaignACL(entry); err != nil {
+		log.Errorf("error setting campaign acl: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	log.Infof("campaign id=%d granted role=%s to subject=%s", entry.CampaignID, entry.Role, entry.Subject)
+}
+
+// AuditHandler returns audit log entries matching the given query,
+// scoped to a single campaign when CampaignID is set. Reading the
+// global audit log (CampaignID == 0) requires a global role, since it
+// is not gated by any one campaign's ACL.
+func (s *Server) AuditHandler(w http.ResponseWriter, r *http.Request) {
+	var q db.Query
+
+	err := parse.DecodeJSONBody(w, r, &q)
+	if err != nil {
+		var mr *parse.MalformedRequest
+		if errors.As(err, &mr) {
+			http.Error(w, mr.Msg, mr.Status)
+		} else {
+			log.Errorf("unknown error decoding json: %s", err)
+			http.Error(w, http.StatusText(500), 500)
+		}
+		return
+	}
+
+	if !s.authorize(w, r, authz.ActionReadAudit, authz.Resource{CampaignID: q.CampaignID}) {
+		return
+	}
+
+	entries, err := s.DB.ListAuditLog(q)
+	if err != nil {
+		log.Errorf("error querying audit log: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&entries); err != nil {
+		log.Errorf("error encoding audit log: %s", err)
+	}
+}
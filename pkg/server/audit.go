@@ -0,0 +1,95 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/praetorian-inc/trident/pkg/db"
+)
+
+// AuditSink records audit log entries to both the database (for the
+// /audit API) and a JSON-lines file (for offline ingestion by a SIEM).
+type AuditSink struct {
+	DB db.Datastore
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewAuditSink opens (creating if necessary) the JSON-lines file at
+// path for appending, and returns a sink that writes every entry there
+// as well as to datastore.
+func NewAuditSink(datastore db.Datastore, path string) (*AuditSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditSink{DB: datastore, file: f}, nil
+}
+
+// Record persists entry to the database and appends it to the
+// JSON-lines file. Failures to write the file are logged but do not
+// fail the request, since the database row remains authoritative.
+func (a *AuditSink) Record(entry db.AuditLogEntry) {
+	if err := a.DB.InsertAuditLog(entry); err != nil {
+		log.WithError(err).Error("failed to write audit log entry to database")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.WithError(err).Error("failed to marshal audit log entry")
+		return
+	}
+	b = append(b, '\n')
+
+	if _, err := a.file.Write(b); err != nil {
+		log.WithError(err).Error("failed to append audit log entry to file sink")
+	}
+}
+
+// auditFields extracts the request metadata every audit entry records:
+// the caller's source IP and a request ID, which handlers read from the
+// standard X-Request-Id header set by the load balancer or proxy.
+func auditFields(r *http.Request) (requestID, sourceIP string) {
+	requestID = r.Header.Get("X-Request-Id")
+	sourceIP = realSourceIP(r)
+	return requestID, sourceIP
+}
+
+// realSourceIP returns the operator's real client IP. Behind the
+// Cloudflare Access/Tunnel deployment (see pkg/auth/cloudflare),
+// r.RemoteAddr is the cloudflared daemon's own loopback-ish address,
+// not the caller's, so CF-Connecting-IP (and, failing that, the first
+// hop of a generic X-Forwarded-For) is preferred when present.
+func realSourceIP(r *http.Request) string {
+	if ip := r.Header.Get("CF-Connecting-IP"); ip != "" {
+		return ip
+	}
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		return strings.TrimSpace(strings.Split(fwd, ",")[0])
+	}
+	return r.RemoteAddr
+}
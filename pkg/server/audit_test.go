@@ -0,0 +1,53 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealSourceIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		cfConnecting string
+		forwardedFor string
+		want         string
+	}{
+		{"no headers falls back to RemoteAddr", "10.0.0.1:54321", "", "", "10.0.0.1:54321"},
+		{"CF-Connecting-IP wins over RemoteAddr", "10.0.0.1:54321", "203.0.113.7", "", "203.0.113.7"},
+		{"X-Forwarded-For used absent CF-Connecting-IP", "10.0.0.1:54321", "", "203.0.113.8, 10.0.0.1", "203.0.113.8"},
+		{"CF-Connecting-IP wins over X-Forwarded-For", "10.0.0.1:54321", "203.0.113.7", "203.0.113.8, 10.0.0.1", "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.cfConnecting != "" {
+				r.Header.Set("CF-Connecting-IP", tt.cfConnecting)
+			}
+			if tt.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+
+			if got := realSourceIP(r); got != tt.want {
+				t.Fatalf("realSourceIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/praetorian-inc/trident/pkg/auth"
+)
+
+// identityContextKey is the context key under which OIDCMiddleware stores
+// the authenticated auth.Identity.
+type identityContextKey struct{}
+
+// OIDCAuthConfig controls how OIDCMiddleware validates bearer tokens.
+type OIDCAuthConfig struct {
+	// IssuerURL is the OIDC provider whose JWKS is used to verify
+	// incoming tokens, e.g. "https://dex.example.com".
+	IssuerURL string
+
+	// Audience is the expected "aud" claim; tokens issued for any other
+	// client are rejected.
+	Audience string
+
+	// ClaimMapping names the claims used to populate auth.Identity,
+	// defaulting to "sub" and "email" when left empty. This lets
+	// providers that emit nonstandard claim names (e.g. "upn") be
+	// supported without code changes.
+	SubjectClaim string
+	EmailClaim   string
+}
+
+// OIDCMiddleware validates the Authorization: Bearer token on every
+// request against the configured provider's JWKS, checking issuer and
+// audience, and attaches the resulting auth.Identity to the request
+// context for downstream handlers (and pkg/authz) to consume.
+func OIDCMiddleware(ctx context.Context, cfg OIDCAuthConfig, next http.Handler) (http.Handler, error) {
+	provider, err := gooidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier := provider.Verifier(&gooidc.Config{ClientID: cfg.Audience})
+
+	subjectClaim := cfg.SubjectClaim
+	if subjectClaim == "" {
+		subjectClaim = "sub"
+	}
+	emailClaim := cfg.EmailClaim
+	if emailClaim == "" {
+		emailClaim = "email"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawToken, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		idToken, err := verifier.Verify(r.Context(), rawToken)
+		if err != nil {
+			log.WithError(err).Warn("rejected request with invalid id token")
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		var claims map[string]interface{}
+		if err := idToken.Claims(&claims); err != nil {
+			log.WithError(err).Error("failed to parse id token claims")
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		identity := auth.Identity{
+			Subject: stringClaim(claims, subjectClaim),
+			Email:   stringClaim(claims, emailClaim),
+			Claims:  claims,
+		}
+
+		ctx := context.WithValue(r.Context(), identityContextKey{}, identity)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}), nil
+}
+
+// IdentityFromContext returns the auth.Identity attached by OIDCMiddleware,
+// if any.
+func IdentityFromContext(ctx context.Context) (auth.Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(auth.Identity)
+	return identity, ok
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(header, prefix), true
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+	v, _ := claims[name].(string)
+	return v
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantToken string
+		wantOK    bool
+	}{
+		{"well formed bearer header", "Bearer abc.def.ghi", "abc.def.ghi", true},
+		{"missing header", "", "", false},
+		{"wrong scheme", "Basic dXNlcjpwYXNz", "", false},
+		{"bearer with empty token", "Bearer ", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			token, ok := bearerToken(r)
+			if ok != tt.wantOK || token != tt.wantToken {
+				t.Fatalf("bearerToken() = (%q, %v), want (%q, %v)", token, ok, tt.wantToken, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestStringClaim(t *testing.T) {
+	claims := map[string]interface{}{"sub": "user-123", "count": 5}
+
+	if got := stringClaim(claims, "sub"); got != "user-123" {
+		t.Fatalf("stringClaim(sub) = %q, want %q", got, "user-123")
+	}
+	if got := stringClaim(claims, "missing"); got != "" {
+		t.Fatalf("stringClaim(missing) = %q, want empty string", got)
+	}
+	if got := stringClaim(claims, "count"); got != "" {
+		t.Fatalf("stringClaim(count) = %q, want empty string for a non-string claim", got)
+	}
+}
+
+func TestIdentityFromContext(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if _, ok := IdentityFromContext(r.Context()); ok {
+		t.Fatal("IdentityFromContext() returned ok=true for a context with no identity attached")
+	}
+}
@@ -21,26 +21,104 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/praetorian-inc/trident/pkg/authz"
 	"github.com/praetorian-inc/trident/pkg/db"
 	"github.com/praetorian-inc/trident/pkg/parse"
-	"github.com/praetorian-inc/trident/pkg/scheduler"
+	"github.com/praetorian-inc/trident/pkg/secrets"
+	"github.com/praetorian-inc/trident/pkg/service"
 )
 
-// Server carries context for the http handlers to work from. it keeps track of
-// the current server's database connection pool and scheduler.
+// Server carries context for the http handlers to work from. it keeps
+// track of the current server's database connection pool and the
+// shared service layer that pkg/grpcserver also adapts. Handlers below
+// are thin: decode the request, authorize + audit, call into Service,
+// encode the response.
 type Server struct {
-	DB  db.Datastore
-	Sch scheduler.Scheduler
+	DB      db.Datastore
+	Service *service.Service
+
+	// Authz is the single policy-evaluation chokepoint every handler
+	// below calls before touching a campaign. Audit records who did
+	// what, for every one of those decisions (allowed or not).
+	Authz *authz.Evaluator
+	Audit *AuditSink
+
+	// Secrets resolves provider credentials out of campaign config
+	// before it is ever written to the database; see resolveProviderCredential.
+	Secrets secrets.SecretStore
+}
+
+// audit records one call into pkg/service to the audit log: who made
+// it, against which campaign, and whether it was ultimately allowed.
+// Handlers call this after the service call returns, regardless of
+// outcome, so the trail covers denials as well as successes.
+func (s *Server) audit(r *http.Request, action authz.Action, campaignID uint, err error) {
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		return
+	}
+
+	requestID, sourceIP := auditFields(r)
+	s.Audit.Record(db.AuditLogEntry{
+		RequestID:  requestID,
+		Subject:    identity.Subject,
+		SourceIP:   sourceIP,
+		Action:     string(action),
+		CampaignID: campaignID,
+		Detail:     denialDetail(err),
+	})
+}
+
+// authorize is the shared guard for handlers that touch pkg/db directly
+// rather than going through pkg/service (ACL grants, the audit log,
+// secret management): it runs the single authz chokepoint, audits the
+// decision, and on denial writes the response itself. Callers should
+// return immediately when it reports false.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, action authz.Action, resource authz.Resource) bool {
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
+		return false
+	}
+
+	err := s.Authz.Check(r.Context(), identity, action, resource)
+	s.audit(r, action, resource.CampaignID, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return false
+	}
+
+	return true
+}
+
+func denialDetail(err error) string {
+	if err == nil {
+		return "allowed"
+	}
+	return err.Error()
+}
+
+// writeServiceError maps an error returned by pkg/service to the HTTP
+// status a caller would expect: 403 when authz.Check denied the
+// request, 500 for everything else (persistence failures, scheduler
+// errors, and so on).
+func writeServiceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, authz.ErrForbidden) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	http.Error(w, http.StatusText(500), 500)
 }
 
 // HealthzHandler is for k8s health checking, this always returns 200
 func (s *Server) HealthzHandler(w http.ResponseWriter, r *http.Request) {}
 
 // CampaignHandler receives data from the user about the desired campaign
-// configuration. it then inserts the associated metadata into the db and
-// schedules the campaign.
+// configuration, then delegates to pkg/service to validate, persist, and
+// schedule it.
 func (s *Server) CampaignHandler(w http.ResponseWriter, r *http.Request) {
 	log.Info("creating campaign")
+
 	var c db.Campaign
 
 	err := parse.DecodeJSONBody(w, r, &c)
@@ -55,24 +133,24 @@ func (s *Server) CampaignHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.DB.InsertCampaign(&c)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"campaign": c,
-		}).Errorf("error inserting campaign: %s", err)
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
 		return
 	}
 
-	go s.Sch.Schedule(c) // nolint:errcheck
-
-	w.Header().Add("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(&c)
+	created, err := s.Service.CreateCampaign(r.Context(), identity, c)
+	s.audit(r, authz.ActionCreateCampaign, 0, err)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"campaign": c,
-		}).Errorf("error encoding campaign for return: %s", err)
+		log.WithFields(log.Fields{"campaign": c}).Errorf("error creating campaign: %s", err)
+		writeServiceError(w, err)
 		return
 	}
+
+	w.Header().Add("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&created); err != nil {
+		log.WithFields(log.Fields{"campaign": created}).Errorf("error encoding campaign for return: %s", err)
+	}
 }
 
 // ResultsHandler takes a user defined database query (returned fields + filter)
@@ -92,46 +170,51 @@ func (s *Server) ResultsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results, err := s.DB.SelectResults(q)
-	if err != nil {
-		log.Printf("error querying database: %s", err)
-		http.Error(w, http.StatusText(500), 500)
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
+		return
 	}
 
-	err = json.NewEncoder(w).Encode(&results)
+	results, err := s.Service.QueryResults(r.Context(), identity, q)
+	s.audit(r, authz.ActionReadResults, q.CampaignID, err)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"results": results,
-		}).Errorf("error encoding results: %s", err)
+		log.Errorf("error querying results: %s", err)
+		writeServiceError(w, err)
 		return
 	}
+
+	if err := json.NewEncoder(w).Encode(&results); err != nil {
+		log.WithFields(log.Fields{"results": results}).Errorf("error encoding results: %s", err)
+	}
 }
 
 // CampaignListHandler accepts no parameters and returns the list of active campaigns
 // via JSON
 func (s *Server) CampaignListHandler(w http.ResponseWriter, r *http.Request) {
-	var campaigns []db.Campaign
-
-	campaigns, err := s.DB.ListCampaign()
-	if err != nil {
-		log.Printf("error querying database: %s", err)
-		http.Error(w, http.StatusText(500), 500)
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
+		return
 	}
 
-	err = json.NewEncoder(w).Encode(&campaigns)
+	campaigns, err := s.Service.ListCampaigns(r.Context(), identity)
+	s.audit(r, authz.ActionReadCampaign, 0, err)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"results": campaigns,
-		}).Errorf("error encoding results: %s", err)
+		log.Errorf("error listing campaigns: %s", err)
+		writeServiceError(w, err)
 		return
 	}
+
+	if err := json.NewEncoder(w).Encode(&campaigns); err != nil {
+		log.WithFields(log.Fields{"results": campaigns}).Errorf("error encoding results: %s", err)
+	}
 }
 
 // CampaignDescribeHandler takes a user-defined DB query with the campaignID, then
 // returns the parameters of that campaign via JSON
 func (s *Server) CampaignDescribeHandler(w http.ResponseWriter, r *http.Request) {
 	var q db.Query
-	var campaign db.Campaign
 
 	err := parse.DecodeJSONBody(w, r, &q)
 	if err != nil {
@@ -145,48 +228,21 @@ func (s *Server) CampaignDescribeHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	campaign, err = s.DB.DescribeCampaign(q)
-	if err != nil {
-		log.Printf("error querying database: %s", err)
-		http.Error(w, http.StatusText(500), 500)
-	}
-
-	err = json.NewEncoder(w).Encode(&campaign)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"campaign": campaign,
-		}).Errorf("error encoding campaign: %s", err)
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
 		return
 	}
-}
-
-// StatusUpdateHandler takes a campaignID from the user, then
-// sets its status based on the post body content.
-func (s *Server) StatusUpdateHandler(w http.ResponseWriter, r *http.Request) {
-	type StatusUpdateHandler struct {
-		ID     uint
-		Status db.CampaignStatus
-	}
-
-	var postBody StatusUpdateHandler
 
-	err := parse.DecodeJSONBody(w, r, &postBody)
+	campaign, err := s.Service.DescribeCampaign(r.Context(), identity, q)
+	s.audit(r, authz.ActionReadCampaign, q.CampaignID, err)
 	if err != nil {
-		var mr *parse.MalformedRequest
-		if errors.As(err, &mr) {
-			http.Error(w, mr.Msg, mr.Status)
-		} else {
-			log.Errorf("unknown error decoding json: %s", err)
-			http.Error(w, http.StatusText(500), 500)
-		}
+		log.Errorf("error describing campaign: %s", err)
+		writeServiceError(w, err)
 		return
 	}
 
-	err = s.DB.UpdateCampaignStatus(postBody.ID, postBody.Status)
-	if err != nil {
-		log.Printf("error updating database: %s", err)
-		http.Error(w, http.StatusText(500), 500)
+	if err := json.NewEncoder(w).Encode(&campaign); err != nil {
+		log.WithFields(log.Fields{"campaign": campaign}).Errorf("error encoding campaign: %s", err)
 	}
-
-	log.Infof("campaign id=%d status has been set to %s", postBody.ID, postBody.Status)
 }
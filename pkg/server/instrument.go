@@ -0,0 +1,59 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/praetorian-inc/trident/pkg/metrics"
+)
+
+var tracer = otel.Tracer("github.com/praetorian-inc/trident/pkg/server")
+
+// Instrument wraps a handler with the Prometheus and tracing
+// instrumentation shared by every pkg/server route: it extracts the
+// caller's W3C trace context (propagated from trident-cli or another
+// service), starts a child span named for the handler, and records
+// trident_http_requests_total{handler,code} once the handler returns.
+// Callers register routes with it in place of the bare handler, e.g.
+// router.HandleFunc("/campaign", server.Instrument("campaign_create", s.CampaignHandler)).
+func Instrument(name string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, "http."+name)
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r.WithContext(ctx))
+
+		metrics.HTTPRequestsTotal.WithLabelValues(name, strconv.Itoa(rec.status)).Inc()
+	}
+}
+
+// statusRecorder captures the status code a handler writes, since
+// http.ResponseWriter doesn't expose it after the fact.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,202 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/praetorian-inc/trident/pkg/authz"
+	"github.com/praetorian-inc/trident/pkg/db"
+	"github.com/praetorian-inc/trident/pkg/metrics"
+)
+
+// campaignID pulls the {id} path variable set by the router and parses
+// it, writing a 400 and returning ok=false if it is missing or invalid.
+func campaignID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	raw, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "missing campaign id", http.StatusBadRequest)
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid campaign id", http.StatusBadRequest)
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// CampaignPauseHandler stops the scheduler from dispatching new buckets
+// for the campaign, without losing its place in the schedule.
+func (s *Server) CampaignPauseHandler(w http.ResponseWriter, r *http.Request) {
+	s.transition(w, r, db.StatusPaused)
+}
+
+// CampaignResumeHandler picks a paused campaign's dispatch back up.
+func (s *Server) CampaignResumeHandler(w http.ResponseWriter, r *http.Request) {
+	s.transition(w, r, db.StatusRunning)
+}
+
+// CampaignCancelHandler stops a campaign's dispatch for good; any
+// in-flight buckets are allowed to finish but no new ones go out.
+func (s *Server) CampaignCancelHandler(w http.ResponseWriter, r *http.Request) {
+	s.transition(w, r, db.StatusCanceled)
+}
+
+// transition is the shared body of the pause/resume/cancel handlers: it
+// resolves the campaign id and identity, then lets pkg/service apply
+// the status transition and the scheduler side effect that goes with it.
+func (s *Server) transition(w http.ResponseWriter, r *http.Request, status db.CampaignStatus) {
+	id, ok := campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
+		return
+	}
+
+	campaign, err := s.Service.UpdateStatus(r.Context(), identity, id, status)
+	s.audit(r, authz.ActionMutateCampaign, id, err)
+	if err != nil {
+		log.Errorf("error transitioning campaign id=%d to %s: %s", id, status, err)
+		writeServiceError(w, err)
+		return
+	}
+
+	log.Infof("campaign id=%d status has been set to %s", id, status)
+
+	if err := json.NewEncoder(w).Encode(&campaign); err != nil {
+		log.Errorf("error encoding campaign: %s", err)
+	}
+}
+
+// CampaignDeleteHandler removes a campaign and its results entirely.
+// The scheduler is canceled first so no new results land mid-delete.
+func (s *Server) CampaignDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
+		return
+	}
+
+	err := s.Authz.Check(r.Context(), identity, authz.ActionMutateCampaign, authz.Resource{CampaignID: id})
+	s.audit(r, authz.ActionMutateCampaign, id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Only a campaign still counted as active (running or paused) needs
+	// to be un-counted here; one already canceled, completed, errored, or
+	// never successfully scheduled was either never incremented or was
+	// already decremented by transition, and double-decrementing would
+	// drift the gauge negative.
+	campaign, err := s.DB.DescribeCampaign(db.Query{CampaignID: id})
+	if err != nil {
+		log.Errorf("error loading campaign id=%d before delete: %s", id, err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+	wasActive := campaign.Status == db.StatusRunning || campaign.Status == db.StatusPaused
+
+	if err := s.Service.Sch.Cancel(id); err != nil {
+		log.Errorf("error canceling campaign id=%d before delete: %s", id, err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+	if wasActive {
+		metrics.ActiveCampaigns.Dec()
+	}
+
+	if err := s.DB.DeleteCampaign(id); err != nil {
+		log.Errorf("error deleting campaign id=%d: %s", id, err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	log.Infof("campaign id=%d deleted", id)
+}
+
+// CampaignCloneHandler copies an existing campaign's user list,
+// password list, provider config, and schedule shape into a new
+// campaign with fresh timestamps and pending status, then schedules it.
+func (s *Server) CampaignCloneHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// Cloning exposes the source campaign's user/password lists and
+	// provider config to the new campaign's owner, so it requires at
+	// least operator on the source, not merely read access to it.
+	err := s.Authz.Check(r.Context(), identity, authz.ActionMutateCampaign, authz.Resource{CampaignID: id})
+	s.audit(r, authz.ActionMutateCampaign, id, err)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	src, err := s.Service.DescribeCampaign(r.Context(), identity, db.Query{CampaignID: id})
+	s.audit(r, authz.ActionReadCampaign, id, err)
+	if err != nil {
+		log.Errorf("error loading campaign id=%d to clone: %s", id, err)
+		writeServiceError(w, err)
+		return
+	}
+
+	clone := db.Campaign{
+		Name:             src.Name + " (clone)",
+		Status:           db.StatusPending,
+		Provider:         src.Provider,
+		Users:            src.Users,
+		Passwords:        src.Passwords,
+		ProviderConfig:   src.ProviderConfig,
+		ScheduleInterval: src.ScheduleInterval,
+	}
+
+	created, err := s.Service.CreateCampaign(r.Context(), identity, clone)
+	s.audit(r, authz.ActionCreateCampaign, 0, err)
+	if err != nil {
+		log.Errorf("error inserting cloned campaign: %s", err)
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&created); err != nil {
+		log.Errorf("error encoding cloned campaign: %s", err)
+	}
+}
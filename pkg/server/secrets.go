@@ -0,0 +1,90 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/praetorian-inc/trident/pkg/authz"
+	"github.com/praetorian-inc/trident/pkg/parse"
+)
+
+// secretRequest is the body accepted by POST /secrets: a plaintext
+// value in, an opaque reference out.
+type secretRequest struct {
+	Value string
+}
+
+// SecretsCreateHandler stores a secret directly, for operators managing
+// credentials out of band from campaign creation (e.g. pre-provisioning
+// a ref to hand to a templated campaign). Requires the same role as
+// creating a campaign, since the two are equally sensitive.
+func (s *Server) SecretsCreateHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, authz.ActionCreateCampaign, authz.Resource{}) {
+		return
+	}
+
+	var req secretRequest
+	if err := parse.DecodeJSONBody(w, r, &req); err != nil {
+		var mr *parse.MalformedRequest
+		if errors.As(err, &mr) {
+			http.Error(w, mr.Msg, mr.Status)
+		} else {
+			log.Errorf("unknown error decoding json: %s", err)
+			http.Error(w, http.StatusText(500), 500)
+		}
+		return
+	}
+
+	ref, err := s.Secrets.Put(r.Context(), req.Value)
+	if err != nil {
+		log.Errorf("error storing secret: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"ref": ref}); err != nil {
+		log.Errorf("error encoding secret ref: %s", err)
+	}
+}
+
+// SecretsDeleteHandler removes the secret at the {id} path variable,
+// where {id} is the reference returned by SecretsCreateHandler (or
+// embedded in a campaign's ProviderConfig) minus its backend prefix.
+func (s *Server) SecretsDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, authz.ActionMutateCampaign, authz.Resource{}) {
+		return
+	}
+
+	ref := mux.Vars(r)["id"]
+	if ref == "" {
+		http.Error(w, "missing secret id", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Secrets.Delete(r.Context(), ref); err != nil {
+		log.Errorf("error deleting secret %s: %s", ref, err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	log.Infof("secret %s deleted", ref)
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/praetorian-inc/trident/pkg/authz"
+	"github.com/praetorian-inc/trident/pkg/db"
+)
+
+// errClientGone is returned by the out callback below to tell
+// Service.StreamResults to stop tailing the bus once a write to the SSE
+// client fails; ResultsStreamHandler only logs it.
+var errClientGone = errors.New("sse client disconnected")
+
+// ResultsStreamHandler serves GET /results/stream?campaign_id=... as a
+// Server-Sent Events stream: it backfills every result already in the
+// database, then tails the scheduler's pub/sub bus for new ones until
+// the client disconnects. Both halves are handled by pkg/service; this
+// handler only adapts the callback to SSE framing.
+func (s *Server) ResultsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	campaignID, err := strconv.ParseUint(r.URL.Query().Get("campaign_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "campaign_id must be a valid campaign id", http.StatusBadRequest)
+		return
+	}
+
+	identity, ok := IdentityFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthenticated", http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Headers and the 200 status are deferred until the first event is
+	// actually written, so a denial or backfill failure (returned by
+	// StreamResults before out is ever called) can still be reported
+	// with the correct status code instead of an empty 200.
+	headersSent := false
+	sendHeaders := func() {
+		if headersSent {
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		headersSent = true
+	}
+
+	err = s.Service.StreamResults(r.Context(), identity, uint(campaignID), func(result db.Result) error {
+		sendHeaders()
+		if !writeResultEvent(w, result) {
+			return errClientGone
+		}
+		flusher.Flush()
+		return nil
+	})
+	s.audit(r, authz.ActionReadResults, uint(campaignID), err)
+	if err != nil && !errors.Is(err, errClientGone) {
+		log.Errorf("error streaming results for campaign id=%d: %s", campaignID, err)
+		if !headersSent {
+			writeServiceError(w, err)
+		}
+	}
+}
+
+func writeResultEvent(w http.ResponseWriter, result db.Result) bool {
+	b, err := json.Marshal(result)
+	if err != nil {
+		log.Errorf("error marshaling result for stream: %s", err)
+		return true
+	}
+
+	_, err = w.Write([]byte("event: result.found\ndata: " + string(b) + "\n\n"))
+	return err == nil
+}
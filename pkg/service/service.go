@@ -0,0 +1,229 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package service holds trident's actual campaign validation and
+// scheduling logic, independent of transport. pkg/server (HTTP) and
+// pkg/grpcserver (gRPC) are both thin adapters over a Service, so the
+// two transports can never drift in what they allow.
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+
+	"github.com/praetorian-inc/trident/pkg/auth"
+	"github.com/praetorian-inc/trident/pkg/authz"
+	"github.com/praetorian-inc/trident/pkg/db"
+	"github.com/praetorian-inc/trident/pkg/metrics"
+	"github.com/praetorian-inc/trident/pkg/scheduler"
+	"github.com/praetorian-inc/trident/pkg/secrets"
+)
+
+var tracer = otel.Tracer("github.com/praetorian-inc/trident/pkg/service")
+
+// Service is trident's transport-agnostic campaign API.
+type Service struct {
+	DB      db.Datastore
+	Sch     scheduler.Scheduler
+	Authz   *authz.Evaluator
+	Secrets secrets.SecretStore
+}
+
+// New returns a Service wired to the given dependencies.
+func New(datastore db.Datastore, sch scheduler.Scheduler, az *authz.Evaluator, store secrets.SecretStore) *Service {
+	return &Service{DB: datastore, Sch: sch, Authz: az, Secrets: store}
+}
+
+// CreateCampaign resolves c's provider credential through the secret
+// store, persists c, grants identity RoleAdmin on it, and schedules it.
+func (s *Service) CreateCampaign(ctx context.Context, identity auth.Identity, c db.Campaign) (db.Campaign, error) {
+	ctx, span := tracer.Start(ctx, "service.CreateCampaign")
+	defer span.End()
+
+	if err := s.Authz.Check(ctx, identity, authz.ActionCreateCampaign, authz.Resource{}); err != nil {
+		return db.Campaign{}, err
+	}
+
+	if err := s.resolveProviderCredential(ctx, &c); err != nil {
+		return db.Campaign{}, fmt.Errorf("storing provider credential: %w", err)
+	}
+
+	if err := s.DB.InsertCampaign(&c); err != nil {
+		return db.Campaign{}, fmt.Errorf("inserting campaign: %w", err)
+	}
+
+	if err := s.DB.SetCampaignACL(db.CampaignACL{CampaignID: c.ID, Subject: identity.Subject, Role: db.RoleAdmin}); err != nil {
+		return db.Campaign{}, fmt.Errorf("granting creator admin: %w", err)
+	}
+
+	metrics.CampaignCreatedTotal.Inc()
+
+	go func() {
+		_, span := tracer.Start(ctx, "scheduler.Schedule")
+		defer span.End()
+
+		start := time.Now()
+		err := s.Sch.Schedule(c)
+		metrics.SchedulerDispatchSeconds.Observe(time.Since(start).Seconds())
+		if err != nil {
+			log.Errorf("error scheduling campaign id=%d: %s", c.ID, err)
+			return
+		}
+		metrics.ActiveCampaigns.Inc()
+	}()
+
+	return c, nil
+}
+
+// ListCampaigns returns every campaign identity is permitted to see:
+// every campaign in the system for a global RoleAdmin, or only the
+// campaigns identity holds a CampaignACL grant on otherwise.
+func (s *Service) ListCampaigns(ctx context.Context, identity auth.Identity) ([]db.Campaign, error) {
+	if err := s.Authz.Check(ctx, identity, authz.ActionReadCampaign, authz.Resource{}); err != nil {
+		return nil, err
+	}
+
+	role, err := s.Authz.GlobalRole(identity)
+	if err != nil {
+		return nil, fmt.Errorf("resolving global role: %w", err)
+	}
+
+	if role == db.RoleAdmin {
+		return s.DB.ListCampaign()
+	}
+
+	return s.DB.ListCampaignsForSubject(identity.Subject)
+}
+
+// DescribeCampaign returns the full configuration of one campaign.
+func (s *Service) DescribeCampaign(ctx context.Context, identity auth.Identity, q db.Query) (db.Campaign, error) {
+	if err := s.Authz.Check(ctx, identity, authz.ActionReadCampaign, authz.Resource{CampaignID: q.CampaignID}); err != nil {
+		return db.Campaign{}, err
+	}
+
+	return s.DB.DescribeCampaign(q)
+}
+
+// UpdateStatus transitions a campaign to status, telling the scheduler
+// to actually start, pause, resume, or stop dispatching it.
+func (s *Service) UpdateStatus(ctx context.Context, identity auth.Identity, campaignID uint, status db.CampaignStatus) (db.Campaign, error) {
+	ctx, span := tracer.Start(ctx, "service.UpdateStatus")
+	defer span.End()
+
+	if err := s.Authz.Check(ctx, identity, authz.ActionMutateCampaign, authz.Resource{CampaignID: campaignID}); err != nil {
+		return db.Campaign{}, err
+	}
+
+	var apply func(uint) error
+	switch status {
+	case db.StatusPaused:
+		apply = s.Sch.Pause
+	case db.StatusRunning:
+		apply = s.Sch.Resume
+	case db.StatusCanceled:
+		apply = s.Sch.Cancel
+	default:
+		return db.Campaign{}, fmt.Errorf("unsupported status transition %q", status)
+	}
+
+	if err := apply(campaignID); err != nil {
+		return db.Campaign{}, fmt.Errorf("transitioning campaign: %w", err)
+	}
+
+	if err := s.DB.UpdateCampaignStatus(campaignID, status); err != nil {
+		return db.Campaign{}, fmt.Errorf("updating campaign status: %w", err)
+	}
+
+	if status == db.StatusCanceled {
+		metrics.ActiveCampaigns.Dec()
+	}
+
+	return s.DB.DescribeCampaign(db.Query{CampaignID: campaignID})
+}
+
+// QueryResults returns the results matching q, scoped to q.CampaignID.
+func (s *Service) QueryResults(ctx context.Context, identity auth.Identity, q db.Query) ([]db.Result, error) {
+	if err := s.Authz.Check(ctx, identity, authz.ActionReadResults, authz.Resource{CampaignID: q.CampaignID}); err != nil {
+		return nil, err
+	}
+
+	return s.DB.SelectResults(q)
+}
+
+// StreamResults backfills q.CampaignID's existing results then tails
+// new ones on the scheduler's pub/sub bus, writing both to out until
+// ctx is canceled. The caller is responsible for closing over out
+// however its transport needs to (an SSE ResponseWriter, a gRPC stream).
+func (s *Service) StreamResults(ctx context.Context, identity auth.Identity, campaignID uint, out func(db.Result) error) error {
+	if err := s.Authz.Check(ctx, identity, authz.ActionReadResults, authz.Resource{CampaignID: campaignID}); err != nil {
+		return err
+	}
+
+	live, unsubscribe := s.Sch.Subscribe(campaignID)
+	defer unsubscribe()
+
+	backfill, err := s.DB.SelectResults(db.Query{CampaignID: campaignID})
+	if err != nil {
+		return fmt.Errorf("backfilling results: %w", err)
+	}
+
+	for _, result := range backfill {
+		if err := out(result); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case result, ok := <-live:
+			if !ok {
+				return nil
+			}
+			if err := out(result); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// resolveProviderCredential replaces the raw value at
+// c.ProviderConfig[secrets.CredentialKey], if present, with an opaque
+// SecretStore reference, so no plaintext credential is ever persisted.
+func (s *Service) resolveProviderCredential(ctx context.Context, c *db.Campaign) error {
+	if c.ProviderConfig == nil {
+		return nil
+	}
+
+	raw, ok := c.ProviderConfig[secrets.CredentialKey].(string)
+	if !ok || raw == "" || strings.Contains(raw, "://") {
+		// Already an opaque ref (e.g. from CampaignCloneHandler reusing
+		// a described campaign's config) rather than a raw credential.
+		return nil
+	}
+
+	ref, err := s.Secrets.Put(ctx, raw)
+	if err != nil {
+		return err
+	}
+
+	c.ProviderConfig[secrets.CredentialKey] = ref
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package telemetry wires up OpenTelemetry tracing shared by
+// trident-cli and the orchestrator, so a single W3C trace-context
+// propagates from a CLI invocation through the HTTP/gRPC request it
+// makes, into pkg/service, and on to the scheduler dispatching it.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// Config controls where spans are exported and how heavily they're
+// sampled. It is read from the same config file/env vars as the rest
+// of trident's settings (see viper keys "telemetry.otlp-endpoint" and
+// "telemetry.sampling-ratio").
+type Config struct {
+	// OTLPEndpoint is the collector trident exports spans to over
+	// OTLP/gRPC, e.g. "otel-collector:4317". Tracing is a no-op if
+	// left empty, so existing deployments don't need a collector to
+	// upgrade.
+	OTLPEndpoint string
+
+	// SamplingRatio is the fraction of traces recorded, from 0 (none)
+	// to 1 (every trace). A nil SamplingRatio means the operator didn't
+	// set telemetry.sampling-ratio at all and defaults to 1; an explicit
+	// 0 disables sampling instead of being silently treated as unset.
+	SamplingRatio *float64
+}
+
+// Init registers the global TracerProvider and W3C trace-context
+// propagator used throughout trident. The propagator is always set, so
+// an incoming traceparent header is preserved even when cfg.OTLPEndpoint
+// is empty and no spans are actually exported. Callers should defer the
+// returned shutdown func to flush any spans buffered at exit.
+func Init(ctx context.Context, serviceName string, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if cfg.OTLPEndpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating otlp exporter: %w", err)
+	}
+
+	ratio := 1.0
+	if cfg.SamplingRatio != nil {
+		ratio = *cfg.SamplingRatio
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("building resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook delivers HMAC-signed campaign events to operator-configured
+// endpoints, so Trident can be wired into Slack/SIEM/SOAR without polling.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/praetorian-inc/trident/pkg/db"
+)
+
+// Event names delivered to a campaign's WebhookURL.
+const (
+	EventResultFound      = "result.found"
+	EventCampaignComplete = "campaign.completed"
+	EventCampaignError    = "campaign.error"
+)
+
+// maxAttempts bounds the exponential-backoff retry loop before a
+// delivery is written to the dead-letter table.
+const maxAttempts = 5
+
+// Dispatcher sends webhook deliveries for a campaign and retries them
+// with exponential backoff, recording exhausted deliveries to DB for
+// operator inspection.
+type Dispatcher struct {
+	DB     db.Datastore
+	Client *http.Client
+}
+
+// NewDispatcher returns a Dispatcher using http.DefaultClient.
+func NewDispatcher(datastore db.Datastore) *Dispatcher {
+	return &Dispatcher{DB: datastore, Client: http.DefaultClient}
+}
+
+// Send delivers event for campaign c, if c has a WebhookURL configured
+// and its WebhookEvents filter (when set) includes event. It blocks
+// through the full retry loop, so callers typically run it in a
+// goroutine.
+func (d *Dispatcher) Send(ctx context.Context, c db.Campaign, event string, payload interface{}) {
+	if c.WebhookURL == "" || !eventEnabled(c, event) {
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"event":       event,
+		"campaign_id": c.ID,
+		"data":        payload,
+	})
+	if err != nil {
+		log.Errorf("error marshaling webhook payload for campaign id=%d: %s", c.ID, err)
+		return
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff(attempt)):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if lastErr = d.deliver(ctx, c, event, body); lastErr == nil {
+			return
+		}
+
+		log.WithError(lastErr).Warnf("webhook delivery attempt %d/%d failed for campaign id=%d event=%s", attempt+1, maxAttempts, c.ID, event)
+	}
+
+	if err := d.DB.InsertWebhookDeadLetter(db.WebhookDeadLetter{
+		CampaignID: c.ID,
+		Event:      event,
+		Payload:    body,
+		LastError:  lastErr.Error(),
+		Attempts:   maxAttempts,
+	}); err != nil {
+		log.Errorf("error recording dead-lettered webhook for campaign id=%d: %s", c.ID, err)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, c db.Campaign, event string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Trident-Event", event)
+	req.Header.Set("X-Trident-Signature", sign(c.WebhookSecret, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of body using secret, in the
+// "sha256=<hex>" form GitHub/Stripe-style webhook consumers expect.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func eventEnabled(c db.Campaign, event string) bool {
+	if len(c.WebhookEvents) == 0 {
+		return true
+	}
+	for _, e := range c.WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns an exponential delay for the given retry attempt
+// (1-indexed), capped at 32s: 1s, 2s, 4s, 8s, ...
+func backoff(attempt int) time.Duration {
+	d := time.Second << uint(attempt-1)
+	if d > 32*time.Second {
+		d = 32 * time.Second
+	}
+	return d
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 Praetorian Security, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/trident/pkg/db"
+)
+
+func TestSignMatchesHMACSHA256(t *testing.T) {
+	body := []byte(`{"event":"result.found"}`)
+	got := sign("shh", body)
+
+	mac := hmac.New(sha256.New, []byte("shh"))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got != want {
+		t.Fatalf("sign() = %q, want %q", got, want)
+	}
+}
+
+func TestSignDiffersByBodyAndSecret(t *testing.T) {
+	body := []byte(`{"event":"result.found"}`)
+
+	if sign("secret-a", body) == sign("secret-b", body) {
+		t.Fatal("sign() produced the same signature under different secrets")
+	}
+	if sign("shh", body) == sign("shh", []byte(`{"event":"campaign.error"}`)) {
+		t.Fatal("sign() produced the same signature for different bodies")
+	}
+	if !strings.HasPrefix(sign("shh", body), "sha256=") {
+		t.Fatalf("sign() = %q, want a \"sha256=\" prefix", sign("shh", body))
+	}
+}
+
+func TestEventEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []string
+		event  string
+		want   bool
+	}{
+		{"empty filter allows everything", nil, EventResultFound, true},
+		{"matching event is allowed", []string{EventResultFound, EventCampaignError}, EventResultFound, true},
+		{"non-matching event is filtered out", []string{EventCampaignError}, EventResultFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := db.Campaign{WebhookEvents: tt.events}
+			if got := eventEnabled(c, tt.event); got != tt.want {
+				t.Fatalf("eventEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffGrowsExponentiallyAndCaps(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 1 * time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{6, 32 * time.Second},
+		{10, 32 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Fatalf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}